@@ -10,119 +10,110 @@ type ArrayEncoder struct {
 	e *Encoder
 }
 
-// Object will marshal an Encodee
+// Object will marshal an Encodee, a nil Encodee is marshaled as null
 func (a *ArrayEncoder) Object(value Encodee) (err error) {
-	if a.e.depth == 0 {
-		// Acquire buffer for this depth
-		a.e.buf = p.Acquire()
-	}
-
-	// Increase depth
-	a.e.depth++
-
-	// Get parent's child value
-	pc := a.e.child
-
-	if a.e.child > 0 {
-		a.e.buf.WriteByte(charComma)
-	}
-
-	// Set child value to 0, since this is a new object
-	a.e.child = 0
-
-	a.e.buf.WriteByte(charOpenCurly)
-
-	if err = value.MarshalJsoon(a.e); err != nil {
+	if isNil(value) {
+		a.Null()
 		return
 	}
 
-	a.e.buf.WriteByte(charCloseCurly)
+	a.e.writeSeparator()
+	return a.e.encodeMember(value, nil)
+}
 
-	if _, err = a.e.w.Write(a.e.buf.Bytes()); err != nil {
+// Array will marshal an array, a nil ArrayEncodee is marshaled as null
+func (a *ArrayEncoder) Array(value ArrayEncodee) (err error) {
+	if isNil(value) {
+		a.Null()
 		return
 	}
 
-	a.e.buf.Reset()
+	a.e.writeSeparator()
+	return a.e.encodeMember(nil, value)
+}
 
-	// Set child value to parent's child value
-	a.e.child = pc
-	a.e.child++
+// String will escape and marshal a string
+func (a *ArrayEncoder) String(value string) {
+	a.e.writeSeparator()
 
-	// Reduce depth to the parent's level
-	a.e.depth--
-	return
+	a.e.buf.WriteByte(charDoubleQuote)
+	a.e.writeEscapedString(value)
+	a.e.buf.WriteByte(charDoubleQuote)
+	a.e.child++
 }
 
-// Array will marshal an array
-func (a *ArrayEncoder) Array(value ArrayEncodee) (err error) {
-	if a.e.depth == 0 {
-		// Acquire buffer for this depth
-		a.e.buf = p.Acquire()
-	}
+// UnsafeString will will marshal a string without escaping
+// Note: Only use this if you are CERTAIN that your value does not contain any quotes, backslashes or control characters
+func (a *ArrayEncoder) UnsafeString(value string) {
+	a.e.writeSeparator()
 
-	// Increase depth
-	a.e.depth++
+	a.e.buf.WriteByte(charDoubleQuote)
+	a.e.buf.WriteString(value)
+	a.e.buf.WriteByte(charDoubleQuote)
+	a.e.child++
+}
 
-	// Get parent's child value
-	pc := a.e.child
+// Number will marshal a number
+func (a *ArrayEncoder) Number(value float64) {
+	a.e.writeSeparator()
 
-	if a.e.child > 0 {
-		a.e.buf.WriteByte(charComma)
-	}
+	a.e.writeFloat(value, 64, -1)
+	a.e.child++
+}
 
-	// Set child value to 0, since this is a new object
-	a.e.child = 0
+// Float32 will marshal a float32 using the shortest representation which round-trips as a float32
+func (a *ArrayEncoder) Float32(value float32) {
+	a.e.writeSeparator()
 
-	a.e.buf.WriteByte(charOpenBracket)
+	a.e.writeFloat(float64(value), 32, -1)
+	a.e.child++
+}
 
-	if err = value.MarshalJsoon(a); err != nil {
-		return
-	}
+// Fixed will marshal a number with a fixed number of digits after the decimal point (e.g. 2 for currency)
+func (a *ArrayEncoder) Fixed(value float64, precision int) {
+	a.e.writeSeparator()
 
-	a.e.buf.WriteByte(charCloseBracket)
+	a.e.writeFloat(value, 64, precision)
+	a.e.child++
+}
 
-	if _, err = a.e.w.Write(a.e.buf.Bytes()); err != nil {
-		return
-	}
+// Int will marshal an int
+func (a *ArrayEncoder) Int(value int) {
+	a.Int64(int64(value))
+}
 
-	a.e.buf.Reset()
+// Int64 will marshal an int64
+func (a *ArrayEncoder) Int64(value int64) {
+	a.e.writeSeparator()
 
-	// Set child value to parent's child value
-	a.e.child = pc
+	a.e.buf.WriteInt64(value)
 	a.e.child++
+}
 
-	// Reduce depth to the parent's level
-	a.e.depth--
-	return
+// Uint will marshal a uint
+func (a *ArrayEncoder) Uint(value uint) {
+	a.Uint64(uint64(value))
 }
 
-// String will marshal a string
-func (a *ArrayEncoder) String(value string) {
-	if a.e.child > 0 {
-		a.e.buf.WriteByte(charComma)
-	}
+// Uint64 will marshal a uint64
+func (a *ArrayEncoder) Uint64(value uint64) {
+	a.e.writeSeparator()
 
-	a.e.buf.WriteByte(charDoubleQuote)
-	a.e.buf.WriteString(value)
-	a.e.buf.WriteByte(charDoubleQuote)
+	a.e.buf.WriteUint64(value)
 	a.e.child++
 }
 
-// Number will marshal a number
-func (a *ArrayEncoder) Number(value float64) {
-	if a.e.child > 0 {
-		a.e.buf.WriteByte(charComma)
-	}
+// Null will marshal a null
+func (a *ArrayEncoder) Null() {
+	a.e.writeSeparator()
 
-	a.e.buf.WriteFloat64(value)
+	a.e.buf.WriteNull()
 	a.e.child++
 }
 
 // Bool will marshal a boolean
 func (a *ArrayEncoder) Bool(value bool) {
-	if a.e.child > 0 {
-		a.e.buf.WriteByte(charComma)
-	}
+	a.e.writeSeparator()
 
 	a.e.buf.WriteBool(value)
 	a.e.child++
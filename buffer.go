@@ -1,6 +1,12 @@
 package jsoon
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+const hexChars = "0123456789abcdef"
 
 func newBuffer() *buffer {
 	return &buffer{
@@ -20,30 +26,126 @@ func (b *buffer) WriteByte(v byte) {
 	b.s = append(b.s, v)
 }
 
+func (b *buffer) WriteRune(v rune) {
+	b.s = utf8.AppendRune(b.s, v)
+}
+
 func (b *buffer) WriteString(v string) {
 	b.s = append(b.s, v...)
 }
 
-func (b *buffer) WriteEscapedString(v string) {
-	var c byte
-	for i := 0; i < len(v); i++ {
-		c = v[i]
-		if c == charDoubleQuote {
-			b.s = append(b.s, charBackslash)
+// WriteEscapedString will write a string with all of the characters which are not permitted within a JSON string escaped
+// Note: Invalid UTF-8 is replaced with the unicode replacement character (U+FFFD), valid will be false when this occurs
+func (b *buffer) WriteEscapedString(v string, escapeLineTerminators bool) (valid bool) {
+	var (
+		// Start of the current run of characters which do not require escaping
+		start int
+		c     byte
+	)
+
+	valid = true
+	for i := 0; i < len(v); {
+		if c = v[i]; c < utf8.RuneSelf {
+			if c >= charSpace && c != charDoubleQuote && c != charBackslash {
+				i++
+				continue
+			}
+
+			b.s = append(b.s, v[start:i]...)
+			b.writeEscapedByte(c)
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(v[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.s = append(b.s, v[start:i]...)
+			b.s = append(b.s, `\ufffd`...)
+			valid = false
+		case escapeLineTerminators && (r == '\u2028' || r == '\u2029'):
+			// Line and paragraph separators are valid JSON, but are not valid within JavaScript strings
+			b.s = append(b.s, v[start:i]...)
+			b.s = append(b.s, `\u202`...)
+			b.s = append(b.s, hexChars[r&0xF])
+		default:
+			i += size
+			continue
+		}
+
+		i += size
+		start = i
+	}
+
+	// Fast path for strings which do not require escaping, the entire string is written at once
+	b.s = append(b.s, v[start:]...)
+	return
+}
+
+func (b *buffer) writeEscapedByte(c byte) {
+	switch c {
+	case charDoubleQuote, charBackslash:
+		b.s = append(b.s, charBackslash, c)
+	case charNewline:
+		b.s = append(b.s, charBackslash, charLowerN)
+	case charCarriageReturn:
+		b.s = append(b.s, charBackslash, charLowerR)
+	case charTab:
+		b.s = append(b.s, charBackslash, charLowerT)
+	case charBackspace:
+		b.s = append(b.s, charBackslash, charLowerB)
+	case charFormFeed:
+		b.s = append(b.s, charBackslash, charLowerF)
+	default:
+		// Remaining control characters are written as unicode escapes
+		b.s = append(b.s, `\u00`...)
+		b.s = append(b.s, hexChars[c>>4], hexChars[c&0xF])
+	}
+}
+
+// WriteFloat will write the shortest representation of a float which round-trips at the provided bit size. Like
+// encoding/json, exponent form is used for very small and very large values
+func (b *buffer) WriteFloat(v float64, bitSize int) {
+	format := byte('f')
+	if abs := math.Abs(v); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) || bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
 		}
+	}
 
-		b.s = append(b.s, c)
+	b.s = strconv.AppendFloat(b.s, v, format, -1, bitSize)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(b.s)
+		if n >= 4 && b.s[n-4] == charLowerE && b.s[n-3] == charHyphen && b.s[n-2] == charZero {
+			b.s[n-2] = b.s[n-1]
+			b.s = b.s[:n-1]
+		}
 	}
 }
 
-func (b *buffer) WriteFloat64(v float64) {
-	b.s = strconv.AppendFloat(b.s, v, 'f', -1, 64)
+// WriteFixed will write a float with a fixed number of digits after the decimal point
+func (b *buffer) WriteFixed(v float64, precision int) {
+	b.s = strconv.AppendFloat(b.s, v, 'f', precision, 64)
+}
+
+func (b *buffer) WriteInt64(v int64) {
+	b.s = strconv.AppendInt(b.s, v, 10)
+}
+
+func (b *buffer) WriteUint64(v uint64) {
+	b.s = strconv.AppendUint(b.s, v, 10)
 }
 
 func (b *buffer) WriteBool(v bool) {
 	b.s = strconv.AppendBool(b.s, v)
 }
 
+func (b *buffer) WriteNull() {
+	b.s = append(b.s, nullBytes[:]...)
+}
+
 func (b *buffer) Bytes() []byte {
 	return b.s
 }
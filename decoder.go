@@ -2,7 +2,10 @@ package jsoon
 
 import (
 	"bufio"
+	"bytes"
 	"io"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 const (
@@ -28,30 +31,59 @@ const (
 )
 
 const (
-	charSpace        = ' '
-	charTab          = '\t'
-	charNewline      = '\n'
-	charDoubleQuote  = '"'
-	charSingleQuote  = '\''
-	charBackslash    = '\\'
-	charOpenCurly    = '{'
-	charCloseCurly   = '}'
-	charOpenBracket  = '['
-	charCloseBracket = ']'
-	charColon        = ':'
-	charComma        = ','
-	charZero         = '0'
-	charNine         = '9'
-	charLowerA       = 'a'
-	charLowerN       = 'n'
-	charLowerZ       = 'z'
-	charUpperA       = 'A'
-	charUpperZ       = 'Z'
-	charLowerT       = 't'
-	charLowerF       = 'f'
-	charHyphen       = '-'
+	nsStart uint8 = iota
+	nsSign
+	nsZero
+	nsInteger
+	nsDot
+	nsFraction
+	nsExponent
+	nsExponentSign
+	nsExponentDigits
 )
 
+const (
+	charSpace          = ' '
+	charTab            = '\t'
+	charNewline        = '\n'
+	charCarriageReturn = '\r'
+	charBackspace      = '\b'
+	charFormFeed       = '\f'
+	charDoubleQuote    = '"'
+	charSingleQuote    = '\''
+	charBackslash      = '\\'
+	charForwardSlash   = '/'
+	charOpenCurly      = '{'
+	charCloseCurly     = '}'
+	charOpenBracket    = '['
+	charCloseBracket   = ']'
+	charColon          = ':'
+	charComma          = ','
+	charZero           = '0'
+	charNine           = '9'
+	charLowerA         = 'a'
+	charLowerB         = 'b'
+	charLowerN         = 'n'
+	charLowerR         = 'r'
+	charLowerZ         = 'z'
+	charUpperA         = 'A'
+	charUpperF         = 'F'
+	charUpperI         = 'I'
+	charUpperN         = 'N'
+	charUpperZ         = 'Z'
+	charLowerT         = 't'
+	charLowerU         = 'u'
+	charLowerF         = 'f'
+	charHyphen         = '-'
+	charPlus           = '+'
+	charPeriod         = '.'
+	charLowerE         = 'e'
+	charUpperE         = 'E'
+)
+
+// Start of the UTF-16 low surrogate range
+const surrogateLow = 0xDC00
+
 // NewDecoder will return a new Decoder
 func NewDecoder(r io.Reader) *Decoder {
 	var (
@@ -59,34 +91,93 @@ func NewDecoder(r io.Reader) *Decoder {
 		ok bool
 	)
 
-	if d.r, ok = r.(ReadByter); !ok {
-		d.r = bufio.NewReader(r)
+	if d.r.r, ok = r.(ReadByter); !ok {
+		d.r.r = bufio.NewReader(r)
 	}
 
-	d.v.d = &d
+	d.v.src = &d
 	return &d
 }
 
 // Decoder handles decoding
 type Decoder struct {
-	r ReadByter
+	// Reader wrapped to track our position within the input
+	r positionReader
 	// key buffer
 	kb *buffer
 	// value buffer
 	vb *buffer
 	// decode count
 	dc int
+	// Accept NaN, Infinity and -Infinity as numbers
+	allowNaN bool
+	// Path to the value currently being decoded
+	path pathStack
 
 	v Value
 }
 
-// Decode will decode
+// SetAllowNaN will set whether or not the non-standard NaN, Infinity and -Infinity literals are accepted as numbers.
+// This allows interoperating with producers such as Python's json module, which emit these literals by default. The
+// setting also applies to DecodeLines
+func (d *Decoder) SetAllowNaN(allow bool) {
+	d.allowNaN = allow
+}
+
+// InputOffset will return the number of bytes consumed from the input
+func (d *Decoder) InputOffset() int64 {
+	return d.r.off
+}
+
+// Buffered will return a reader of the data remaining in the Decoder's buffer
+// Note: Data is only buffered when the Decoder wraps the provided reader with a bufio.Reader (or when a bufio.Reader
+// is provided), otherwise any unread data remains within the provided reader
+func (d *Decoder) Buffered() io.Reader {
+	br, ok := d.r.r.(*bufio.Reader)
+	if !ok {
+		return bytes.NewReader(nil)
+	}
+
+	buf, _ := br.Peek(br.Buffered())
+	return bytes.NewReader(buf)
+}
+
+// More will return whether or not another value follows, either within the object or array currently being decoded
+// or as a subsequent document within the input. Any whitespace preceding the next value is consumed
+func (d *Decoder) More() bool {
+	var (
+		b   byte
+		err error
+	)
+
+	for {
+		if b, err = d.r.ReadByte(); err != nil {
+			return false
+		}
+
+		if !isWhitespace(b) {
+			break
+		}
+	}
+
+	if err = d.r.UnreadByte(); err != nil {
+		return false
+	}
+
+	return b != charCloseCurly && b != charCloseBracket
+}
+
+// Decode will decode the next object or array from the input. Decode may be called repeatedly to decode consecutive
+// documents, io.EOF is returned once the input ends cleanly between documents
+// Note: After a syntax error the input is no longer at a document boundary, DecodeLines should be used when bad
+// documents need to be skipped
 func (d *Decoder) Decode(value interface{}) (err error) {
 	var b byte
 
 	if d.dc == 0 {
 		d.kb = p.Acquire()
 		d.vb = p.Acquire()
+		d.path.reset()
 	}
 	d.dc++
 
@@ -109,7 +200,7 @@ func (d *Decoder) Decode(value interface{}) (err error) {
 		case charOpenBracket:
 			dec, ok := value.(ArrayDecodee)
 			if !ok {
-				err = ErrInvalidChar
+				err = ErrInvalidValue
 				goto END
 			}
 
@@ -117,7 +208,7 @@ func (d *Decoder) Decode(value interface{}) (err error) {
 			goto END
 
 		default:
-			err = ErrInvalidChar
+			err = d.r.syntaxError(ErrInvalidChar, TokenObjectOrArray)
 			goto END
 		}
 	}
@@ -139,12 +230,13 @@ func (d *Decoder) decodeObject(dec Decodee) (err error) {
 		b byte
 		// State of our state machine
 		state uint8
-		// Value helper
-	//	val Value
+		// Number of values decoded
+		cnt int
+		// Type of the value passed to the callback, the Value is reused by nested decodes
+		vt ValueType
 	)
 
-	//	val.d = d
-
+	d.kb.Reset()
 	for b, err = d.r.ReadByte(); err == nil; b, err = d.r.ReadByte() {
 		switch state {
 		case osStart:
@@ -152,6 +244,12 @@ func (d *Decoder) decodeObject(dec Decodee) (err error) {
 				continue
 			}
 
+			if b == charCloseCurly && cnt == 0 {
+				// Empty object
+				state = osEnd
+				goto END
+			}
+
 			if b != charDoubleQuote {
 				err = ErrInvalidChar
 				goto END
@@ -160,13 +258,27 @@ func (d *Decoder) decodeObject(dec Decodee) (err error) {
 			state = osKey
 
 		case osKey:
-			if b == charDoubleQuote {
+			switch b {
+			case charDoubleQuote:
 				state = osPreSeparator
-				continue
+			case charBackslash:
+				if b, err = d.r.ReadByte(); err != nil {
+					goto END
+				}
+
+				if err = appendEscape(&d.r, d.kb, b); err != nil {
+					goto END
+				}
+			default:
+				if b < charSpace {
+					// Control characters must be escaped within strings
+					err = ErrInvalidChar
+					goto END
+				}
+
+				d.kb.WriteByte(b)
 			}
 
-			d.kb.WriteByte(b)
-
 		case osPreSeparator:
 			if isWhitespace(b) {
 				continue
@@ -184,19 +296,29 @@ func (d *Decoder) decodeObject(dec Decodee) (err error) {
 				goto END
 			}
 
+			d.v.b = d.vb.Bytes()
+			d.path.pushKey(d.kb.Bytes())
+			vt = d.v.vt
 			if err = dec.UnmarshalJsoon(unsafeString(d.kb.Bytes()), &d.v); err != nil {
+				err = d.path.fieldError(err, vt)
 				goto END
 			}
 
-			//val.vt = valNil
-			d.v.vt = valNil
+			// Ensure objects and arrays which were not handled by the Decodee are skipped
+			if err = d.v.Skip(); err != nil {
+				goto END
+			}
+
+			d.path.pop()
+			d.v.reset()
 			d.kb.Reset()
 			d.vb.Reset()
+			cnt++
 			state = osPostValue
 
 		case osPostValue:
 			if isWhitespace(b) {
-				state = osEnd
+				continue
 			} else if b == charComma {
 				state = osStart
 			} else if b == charCloseCurly {
@@ -206,31 +328,19 @@ func (d *Decoder) decodeObject(dec Decodee) (err error) {
 				err = ErrInvalidChar
 				goto END
 			}
-
-		case osEnd:
-			if isWhitespace(b) {
-				continue
-			}
-
-			if b != charCloseCurly {
-				err = ErrInvalidChar
-			}
-
-			goto END
 		}
 	}
 
 END:
+	if err == nil || err == io.EOF {
+		if state == osEnd {
+			return nil
+		}
 
-	if state != osEnd {
-		return ErrUnexpectedEnd
-	}
-
-	if err == io.EOF {
-		err = nil
+		err = ErrUnexpectedEnd
 	}
 
-	return
+	return d.r.syntaxError(err, objectExpectation(state))
 }
 
 func (d *Decoder) decodeArray(dec ArrayDecodee) (err error) {
@@ -239,12 +349,13 @@ func (d *Decoder) decodeArray(dec ArrayDecodee) (err error) {
 		b byte
 		// State of our state machine
 		state uint8
-		// Value helper
-	//	val Value
+		// Number of values decoded
+		cnt int
+		// Type of the value passed to the callback, the Value is reused by nested decodes
+		vt ValueType
 	)
 
-	//val.d = d
-
+	d.kb.Reset()
 	for b, err = d.r.ReadByte(); err == nil; b, err = d.r.ReadByte() {
 		switch state {
 		case asStart:
@@ -252,22 +363,38 @@ func (d *Decoder) decodeArray(dec ArrayDecodee) (err error) {
 				continue
 			}
 
+			if b == charCloseBracket && cnt == 0 {
+				// Empty array
+				state = asEnd
+				goto END
+			}
+
 			if d.v.vt, err = d.appendValue(b); err != nil {
-				return
+				goto END
 			}
 
+			d.v.b = d.vb.Bytes()
+			d.path.pushIndex(cnt)
+			vt = d.v.vt
 			if err = dec.UnmarshalJsoon(&d.v); err != nil {
-				return
+				err = d.path.fieldError(err, vt)
+				goto END
+			}
+
+			// Ensure objects and arrays which were not handled by the ArrayDecodee are skipped
+			if err = d.v.Skip(); err != nil {
+				goto END
 			}
 
-			//val.vt = valNil
-			d.v.vt = valNil
+			d.path.pop()
+			d.v.reset()
 			d.vb.Reset()
+			cnt++
 			state = asPostValue
 
 		case asPostValue:
 			if isWhitespace(b) {
-				state = asEnd
+				continue
 			} else if b == charComma {
 				state = asStart
 			} else if b == charCloseBracket {
@@ -277,32 +404,88 @@ func (d *Decoder) decodeArray(dec ArrayDecodee) (err error) {
 				err = ErrInvalidChar
 				goto END
 			}
-
-		case asEnd:
-			if isWhitespace(b) {
-				continue
-			} else if b == charCloseBracket {
-				goto END
-			} else {
-				err = ErrInvalidChar
-				goto END
-			}
 		}
 	}
 
 END:
-	if state != asEnd {
-		return ErrUnexpectedEnd
+	if err == nil || err == io.EOF {
+		if state == asEnd {
+			return nil
+		}
+
+		err = ErrUnexpectedEnd
 	}
 
-	if err == io.EOF {
-		err = nil
+	return d.r.syntaxError(err, arrayExpectation(state))
+}
+
+// objectExpectation will return the token class expected by an object state
+func objectExpectation(state uint8) TokenClass {
+	switch state {
+	case osStart:
+		return TokenKey
+	case osKey:
+		return TokenString
+	case osPreSeparator:
+		return TokenColon
+	case osPostValue:
+		return TokenObjectSeparator
 	}
 
-	return
+	return TokenValue
+}
+
+// arrayExpectation will return the token class expected by an array state
+func arrayExpectation(state uint8) TokenClass {
+	if state == asPostValue {
+		return TokenArraySeparator
+	}
+
+	return TokenValue
+}
+
+// skip will skip the remainder of an object or array whose opening character has already been read
+func (d *Decoder) skip() (err error) {
+	var (
+		b byte
+		// Current nesting depth, starts at one to account for the opening character
+		depth = 1
+		// Whether or not we are currently within a string
+		inString bool
+		// Whether or not the previous character was an escaping backslash
+		escaped bool
+	)
+
+	for b, err = d.r.ReadByte(); err == nil; b, err = d.r.ReadByte() {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == charBackslash:
+				escaped = true
+			case b == charDoubleQuote:
+				inString = false
+			}
+
+			continue
+		}
+
+		switch b {
+		case charDoubleQuote:
+			inString = true
+		case charOpenCurly, charOpenBracket:
+			depth++
+		case charCloseCurly, charCloseBracket:
+			if depth--; depth == 0 {
+				return
+			}
+		}
+	}
+
+	return unexpectedEnd(err)
 }
 
-func (d *Decoder) appendValue(lead byte) (vt uint8, err error) {
+func (d *Decoder) appendValue(lead byte) (vt ValueType, err error) {
 	var b byte
 	for b = lead; err == nil; b, err = d.r.ReadByte() {
 		if isWhitespace(b) {
@@ -311,34 +494,52 @@ func (d *Decoder) appendValue(lead byte) (vt uint8, err error) {
 
 		switch b {
 		case charDoubleQuote:
-			vt = valString
+			vt = TypeString
 			err = d.appendString()
 
 		case charLowerT:
-			vt = valBool
+			vt = TypeBool
 			err = d.appendTrue()
 
 		case charLowerF:
-			vt = valBool
+			vt = TypeBool
 			err = d.appendFalse()
 
 		case charOpenCurly:
-			vt = valObject
+			vt = TypeObject
 			return
 
 		case charOpenBracket:
-			vt = valArray
+			vt = TypeArray
 			return
 
 		case charLowerN:
-			vt = valNil
+			vt = TypeNull
 			err = d.readNull()
 			return
 
+		case charUpperN:
+			if !d.allowNaN {
+				err = ErrInvalidChar
+				return
+			}
+
+			vt = TypeNumber
+			err = d.appendLiteral(nanBytes[:])
+
+		case charUpperI:
+			if !d.allowNaN {
+				err = ErrInvalidChar
+				return
+			}
+
+			vt = TypeNumber
+			err = d.appendLiteral(infinityBytes[:])
+
 		default:
 			// TODO: Figure out a cleaner way to perform this check
 			if isNumber(b) || b == charHyphen {
-				vt = valNumber
+				vt = TypeNumber
 				err = d.appendNumber(b)
 			} else {
 				err = ErrInvalidChar
@@ -352,69 +553,84 @@ func (d *Decoder) appendValue(lead byte) (vt uint8, err error) {
 }
 
 func (d *Decoder) appendString() (err error) {
-	var (
-		b       byte
-		escaped bool
-	)
-
+	var b byte
 	for b, err = d.r.ReadByte(); err == nil; b, err = d.r.ReadByte() {
-		if b == charDoubleQuote && !escaped {
+		switch b {
+		case charDoubleQuote:
 			return
-		}
+		case charBackslash:
+			if b, err = d.r.ReadByte(); err != nil {
+				return d.r.syntaxError(unexpectedEnd(err), TokenEscape)
+			}
 
-		if !escaped && b == charBackslash {
-			escaped = true
-			continue
-		}
+			if err = appendEscape(&d.r, d.vb, b); err != nil {
+				return d.r.syntaxError(err, TokenEscape)
+			}
+		default:
+			if b < charSpace {
+				// Control characters must be escaped within strings
+				return d.r.syntaxError(ErrInvalidChar, TokenString)
+			}
 
-		d.vb.WriteByte(b)
-		escaped = false
+			d.vb.WriteByte(b)
+		}
 	}
 
-	return ErrUnexpectedEnd
+	return d.r.syntaxError(unexpectedEnd(err), TokenString)
 }
 
 func (d *Decoder) appendNumber(lead byte) (err error) {
 	var (
-		b   byte
-		cnt int
+		b byte
+		// State of our state machine
+		state uint8
+		// Whether or not the current byte continues the number
+		ok bool
 	)
 
 	for b = lead; err == nil; b, err = d.r.ReadByte() {
-		cnt++
-		if isNumber(b) {
-			d.vb.WriteByte(b)
-			continue
-		}
+		if state, ok = stepNumber(state, b); !ok {
+			if state == nsSign && b == charUpperI && d.allowNaN {
+				// Negative infinity
+				return d.appendLiteral(infinityBytes[:])
+			}
 
-		switch b {
-		case charSpace, charNewline, charTab:
-			return
-		case charComma, charCloseCurly, charCloseBracket:
-			// TODO: Figure out a way to remove this UnreadByte
-			d.r.UnreadByte()
-			return
-		default:
-			if cnt == 1 && b == charHyphen {
-				d.vb.WriteByte(b)
-				continue
+			if !isNumberComplete(state) || !isNumberEnd(b) {
+				// Invalid character found, expected a number or a number-ending character
+				return ErrInvalidNumber
 			}
-			// Invalid character found, expected a number or a number-ending character
-			return ErrInvalidChar
+
+			if isWhitespace(b) {
+				return
+			}
+
+			// TODO: Figure out a way to remove this UnreadByte
+			return d.r.UnreadByte()
 		}
+
+		d.vb.WriteByte(b)
+	}
+
+	if err != io.EOF {
+		return
+	}
+
+	if isNumberComplete(state) {
+		// Input ended on a complete number, let the caller handle the end of input
+		return nil
 	}
 
 	// If we made it through the loop without finding the end to the number, we ended too early
-	return ErrUnexpectedEnd
+	return d.r.syntaxError(ErrUnexpectedEnd, TokenNumber)
 }
 
 func (d *Decoder) appendTrue() (err error) {
 	var b byte
 	for i := 1; i < 4; i++ {
 		if b, err = d.r.ReadByte(); err != nil {
-			return
+			return d.r.syntaxError(unexpectedEnd(err), TokenLiteral)
 		} else if b != trueBytes[i] {
-			return ErrInvalidChar
+			return d.r.syntaxError(ErrInvalidChar, TokenLiteral)
 		}
 	}
 
@@ -426,9 +642,9 @@ func (d *Decoder) appendFalse() (err error) {
 	var b byte
 	for i := 1; i < 5; i++ {
 		if b, err = d.r.ReadByte(); err != nil {
-			return
+			return d.r.syntaxError(unexpectedEnd(err), TokenLiteral)
 		} else if b != falseBytes[i] {
-			return ErrInvalidChar
+			return d.r.syntaxError(ErrInvalidChar, TokenLiteral)
 		}
 	}
 
@@ -436,15 +652,232 @@ func (d *Decoder) appendFalse() (err error) {
 	return
 }
 
+// appendLiteral will append a literal whose first byte has already been read, ensuring each byte matches
+func (d *Decoder) appendLiteral(literal []byte) (err error) {
+	var b byte
+	for i := 1; i < len(literal); i++ {
+		if b, err = d.r.ReadByte(); err != nil {
+			return d.r.syntaxError(unexpectedEnd(err), TokenLiteral)
+		} else if b != literal[i] {
+			return d.r.syntaxError(ErrInvalidChar, TokenLiteral)
+		}
+	}
+
+	d.vb.Write(literal)
+	return
+}
+
 func (d *Decoder) readNull() (err error) {
 	var b byte
 	for i := 1; i < 4; i++ {
 		if b, err = d.r.ReadByte(); err != nil {
-			return
+			return d.r.syntaxError(unexpectedEnd(err), TokenLiteral)
 		} else if b != nullBytes[i] {
-			return ErrInvalidChar
+			return d.r.syntaxError(ErrInvalidChar, TokenLiteral)
+		}
+	}
+
+	return
+}
+
+// appendEscape will append the character represented by an escape sequence, the lead is the byte following the backslash
+func appendEscape(r io.ByteScanner, dst *buffer, lead byte) (err error) {
+	switch lead {
+	case charDoubleQuote, charBackslash, charForwardSlash:
+		dst.WriteByte(lead)
+	case charLowerB:
+		dst.WriteByte(charBackspace)
+	case charLowerF:
+		dst.WriteByte(charFormFeed)
+	case charLowerN:
+		dst.WriteByte(charNewline)
+	case charLowerR:
+		dst.WriteByte(charCarriageReturn)
+	case charLowerT:
+		dst.WriteByte(charTab)
+	case charLowerU:
+		var u rune
+		if u, err = readHex(r); err != nil {
+			return
+		}
+
+		return appendUnicode(r, dst, u)
+	default:
+		return ErrInvalidEscape
+	}
+
+	return
+}
+
+// appendUnicode will append a rune from a unicode escape, pairing UTF-16 surrogates with the escape which follows
+// Note: Unpaired surrogates are replaced with the unicode replacement character (U+FFFD)
+func appendUnicode(r io.ByteScanner, dst *buffer, u rune) (err error) {
+	if !utf16.IsSurrogate(u) {
+		dst.WriteRune(u)
+		return
+	}
+
+	if u >= surrogateLow {
+		// Low surrogate without a preceding high surrogate
+		dst.WriteRune(utf8.RuneError)
+		return
+	}
+
+	var b byte
+	if b, err = r.ReadByte(); err != nil {
+		return unexpectedEnd(err)
+	}
+
+	if b != charBackslash {
+		dst.WriteRune(utf8.RuneError)
+		return r.UnreadByte()
+	}
+
+	if b, err = r.ReadByte(); err != nil {
+		return unexpectedEnd(err)
+	}
+
+	if b != charLowerU {
+		dst.WriteRune(utf8.RuneError)
+		return appendEscape(r, dst, b)
+	}
+
+	var low rune
+	if low, err = readHex(r); err != nil {
+		return
+	}
+
+	if pr := utf16.DecodeRune(u, low); pr != utf8.RuneError {
+		dst.WriteRune(pr)
+		return
+	}
+
+	// The following escape was not a low surrogate, so our high surrogate is unpaired
+	dst.WriteRune(utf8.RuneError)
+	return appendUnicode(r, dst, low)
+}
+
+// readHex will read the four hex digits of a unicode escape
+func readHex(r io.ByteScanner) (u rune, err error) {
+	var b byte
+	for i := 0; i < 4; i++ {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, unexpectedEnd(err)
 		}
+
+		switch {
+		case isNumber(b):
+			b -= charZero
+		case b >= charLowerA && b <= charLowerF:
+			b = b - charLowerA + 10
+		case b >= charUpperA && b <= charUpperF:
+			b = b - charUpperA + 10
+		default:
+			return 0, ErrInvalidEscape
+		}
+
+		u = u<<4 | rune(b)
 	}
 
 	return
 }
+
+// stepNumber will return the next state of the number state machine for the provided byte
+// Note: When ok is false, the byte does not continue the number and state is returned unchanged
+func stepNumber(state uint8, b byte) (next uint8, ok bool) {
+	switch state {
+	case nsStart:
+		switch {
+		case b == charHyphen:
+			return nsSign, true
+		case b == charZero:
+			return nsZero, true
+		case isNumber(b):
+			return nsInteger, true
+		}
+
+	case nsSign:
+		// A sign must be followed by at least one digit
+		switch {
+		case b == charZero:
+			return nsZero, true
+		case isNumber(b):
+			return nsInteger, true
+		}
+
+	case nsZero:
+		// Leading zeros are not permitted, so a zero can only be followed by a fraction or an exponent
+		switch {
+		case b == charPeriod:
+			return nsDot, true
+		case b == charLowerE || b == charUpperE:
+			return nsExponent, true
+		}
+
+	case nsInteger:
+		switch {
+		case isNumber(b):
+			return nsInteger, true
+		case b == charPeriod:
+			return nsDot, true
+		case b == charLowerE || b == charUpperE:
+			return nsExponent, true
+		}
+
+	case nsDot:
+		// A decimal point must be followed by at least one digit
+		if isNumber(b) {
+			return nsFraction, true
+		}
+
+	case nsFraction:
+		switch {
+		case isNumber(b):
+			return nsFraction, true
+		case b == charLowerE || b == charUpperE:
+			return nsExponent, true
+		}
+
+	case nsExponent:
+		// An exponent must contain at least one digit
+		switch {
+		case b == charHyphen || b == charPlus:
+			return nsExponentSign, true
+		case isNumber(b):
+			return nsExponentDigits, true
+		}
+
+	case nsExponentSign:
+		// An exponent sign must be followed by at least one digit
+		if isNumber(b) {
+			return nsExponentDigits, true
+		}
+
+	case nsExponentDigits:
+		if isNumber(b) {
+			return nsExponentDigits, true
+		}
+	}
+
+	return state, false
+}
+
+// isNumberComplete will return whether or not a number state represents a complete number
+func isNumberComplete(state uint8) bool {
+	switch state {
+	case nsZero, nsInteger, nsFraction, nsExponentDigits:
+		return true
+	}
+
+	return false
+}
+
+// isNumberEnd will return whether or not a byte is permitted to directly follow a number
+func isNumberEnd(b byte) bool {
+	switch b {
+	case charSpace, charNewline, charTab, charCarriageReturn, charComma, charCloseCurly, charCloseBracket:
+		return true
+	}
+
+	return false
+}
@@ -1,6 +1,23 @@
 package jsoon
 
-import "io"
+import (
+	"io"
+	"math"
+)
+
+// FloatPolicy determines how NaN and infinite floats, which cannot be represented in JSON, are encoded
+type FloatPolicy uint8
+
+const (
+	// FloatPolicyError will cause the encode to fail with ErrInvalidFloat
+	FloatPolicyError FloatPolicy = iota
+	// FloatPolicyNull will encode the value as null
+	FloatPolicyNull
+	// FloatPolicyString will encode the value as the string "NaN", "Infinity" or "-Infinity"
+	FloatPolicyString
+	// FloatPolicyClamp will encode infinite values as the largest finite float of the same sign, and NaN as 0
+	FloatPolicyClamp
+)
 
 // NewEncoder will return a new encoder
 func NewEncoder(w io.Writer) *Encoder {
@@ -17,205 +34,520 @@ type Encoder struct {
 
 	depth int
 	child int
+
+	// Escape U+2028 and U+2029 within strings
+	escapeLineTerminators bool
+	// Write keys without escaping
+	unsafeKeys bool
+	// Buffered byte count which will cause a document to be written before it is complete
+	flushThreshold int
+	// Whether or not output is indented
+	indenting bool
+	// Prefix written at the start of each indented line
+	prefix string
+	// Indent written for each level of nesting
+	indent string
+	// Policy for NaN and infinite floats
+	floatPolicy FloatPolicy
+	// Maximum depth of nested objects and arrays, zero represents no limit
+	maxDepth int
+	// Return an error for invalid UTF-8 rather than replacing it
+	strictUTF8 bool
+	// Terminate each top-level document with a newline, used by LineEncoder
+	lineDelimited bool
+
+	// Sticky error, the first error encountered while encoding the current document
+	err error
+}
+
+// Reset will discard any partially encoded document and set the writer, allowing the Encoder to be reused
+// Note: Options set on the Encoder are retained
+func (e *Encoder) Reset(w io.Writer) {
+	e.rollback()
+	e.w = w
+	e.err = nil
+}
+
+// Err will return the first error encountered while encoding the current (or most recent) document. Methods without
+// an error return (such as String and Number) record their errors here, as do nested Object and Array values whose
+// MarshalJsoon fails, and the error is then returned by Encode
+func (e *Encoder) Err() error {
+	return e.err
+}
+
+// SetIndent will set the encoder to write indented output. Each element of an object or array begins on a new line
+// starting with prefix, followed by one copy of indent per level of nesting. Empty objects and arrays remain compact.
+// Calling SetIndent with an empty prefix and indent will restore compact output
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+	e.indenting = prefix != "" || indent != ""
+}
+
+// SetFloatPolicy will set how NaN and infinite floats are encoded, by default they cause the encode to fail
+func (e *Encoder) SetFloatPolicy(policy FloatPolicy) {
+	e.floatPolicy = policy
+}
+
+// SetMaxDepth will set the maximum depth of nested objects and arrays, zero represents no limit
+func (e *Encoder) SetMaxDepth(n int) {
+	e.maxDepth = n
+}
+
+// SetStrictUTF8 will set whether or not strings containing invalid UTF-8 cause the encode to fail. By default,
+// invalid UTF-8 is replaced with the unicode replacement character (U+FFFD)
+func (e *Encoder) SetStrictUTF8(strict bool) {
+	e.strictUTF8 = strict
+}
+
+// SetEscapeLineTerminators will set whether or not the line separator (U+2028) and the paragraph separator (U+2029)
+// are escaped within strings. These characters are valid JSON, but will break JSON which is embedded within JavaScript
+func (e *Encoder) SetEscapeLineTerminators(escape bool) {
+	e.escapeLineTerminators = escape
 }
 
-// Encode will marshal an Encodee
+// SetUnsafeKeys will set whether or not object keys are written without escaping. Keys consisting of plain ASCII
+// are already written without escaping, so this only skips the check performed for every key
+// Note: Only use this if you are CERTAIN that your keys do not contain any quotes, backslashes or control characters
+func (e *Encoder) SetUnsafeKeys(unsafe bool) {
+	e.unsafeKeys = unsafe
+}
+
+// SetFlushThreshold will set the number of buffered bytes which will cause a document to be partially written
+// before it is complete. By default (zero), each document is written with a single Write call once it is complete
+// Note: Output which has already been written cannot be rolled back, so when an encode fails the writer may be left
+// with an incomplete document. Once an error is encountered nothing further is written for that document
+func (e *Encoder) SetFlushThreshold(n int) {
+	e.flushThreshold = n
+}
+
+// Encode will marshal an Encodee, a nil Encodee is marshaled as null
+// Note: If an error is returned (or a panic occurs) while encoding, the partial document is discarded. When a flush
+// threshold is set, any portion written before the failure remains with the writer (see SetFlushThreshold)
 func (e *Encoder) Encode(value Encodee) (err error) {
-	if e.depth == 0 {
-		// Acquire buffer for this depth
-		e.buf = p.Acquire()
+	if isNil(value) {
+		return e.EncodeNull()
 	}
 
-	// Increase depth
-	e.depth++
+	return e.encodeDocument(value, nil)
+}
 
-	// Get parent's child value
-	pc := e.child
+// EncodeArray will marshal an ArrayEncodee as a top-level array, a nil ArrayEncodee is marshaled as null
+// Note: If an error is returned (or a panic occurs) while encoding, the partial document is discarded. When a flush
+// threshold is set, any portion written before the failure remains with the writer (see SetFlushThreshold)
+func (e *Encoder) EncodeArray(value ArrayEncodee) (err error) {
+	if isNil(value) {
+		return e.EncodeNull()
+	}
 
-	// Set child value to 0, since this is a new object
-	e.child = 0
+	return e.encodeDocument(nil, value)
+}
 
-	e.buf.WriteByte(charOpenCurly)
+// EncodeString will escape and marshal a top-level string
+func (e *Encoder) EncodeString(value string) (err error) {
+	if err = e.openDocument(); err != nil {
+		return
+	}
+
+	e.buf.WriteByte(charDoubleQuote)
+	e.writeEscapedString(value)
+	e.buf.WriteByte(charDoubleQuote)
+	return e.close()
+}
 
-	if err = value.MarshalJsoon(e); err != nil {
+// EncodeNumber will marshal a top-level number
+func (e *Encoder) EncodeNumber(value float64) (err error) {
+	if err = e.openDocument(); err != nil {
 		return
 	}
 
-	e.buf.WriteByte(charCloseCurly)
+	e.writeFloat(value, 64, -1)
+	return e.close()
+}
 
-	if _, err = e.w.Write(e.buf.Bytes()); err != nil {
+// EncodeInt64 will marshal a top-level int64
+func (e *Encoder) EncodeInt64(value int64) (err error) {
+	if err = e.openDocument(); err != nil {
 		return
 	}
 
-	e.buf.Reset()
+	e.buf.WriteInt64(value)
+	return e.close()
+}
 
-	// Set child value to parent's child value
-	e.child = pc
+// EncodeUint64 will marshal a top-level uint64
+func (e *Encoder) EncodeUint64(value uint64) (err error) {
+	if err = e.openDocument(); err != nil {
+		return
+	}
 
-	// Reduce depth to the parent's level
-	e.depth--
+	e.buf.WriteUint64(value)
+	return e.close()
+}
 
-	if e.depth == 0 {
-		p.Release(e.buf)
-		e.buf = nil
+// EncodeBool will marshal a top-level boolean
+func (e *Encoder) EncodeBool(value bool) (err error) {
+	if err = e.openDocument(); err != nil {
+		return
 	}
 
-	return
+	e.buf.WriteBool(value)
+	return e.close()
+}
+
+// EncodeNull will marshal a top-level null
+func (e *Encoder) EncodeNull() (err error) {
+	if err = e.openDocument(); err != nil {
+		return
+	}
+
+	e.buf.WriteNull()
+	return e.close()
 }
 
-// Object will marshal an Encodee
+// Object will marshal an Encodee, a nil Encodee is marshaled as null
 func (e *Encoder) Object(key string, value Encodee) (err error) {
-	if e.depth == 0 {
-		// Acquire buffer for this depth
-		e.buf = p.Acquire()
+	if isNil(value) {
+		e.Null(key)
+		return
 	}
 
-	// Increase depth
-	e.depth++
+	e.writeKey(key)
+	return e.encodeMember(value, nil)
+}
 
-	// Get parent's child value
-	pc := e.child
+// Array will marshal an array, a nil ArrayEncodee is marshaled as null
+func (e *Encoder) Array(key string, value ArrayEncodee) (err error) {
+	if isNil(value) {
+		e.Null(key)
+		return
+	}
+
+	e.writeKey(key)
+	return e.encodeMember(nil, value)
+}
+
+// String will escape and marshal a string
+func (e *Encoder) String(key, value string) {
+	e.writeKey(key)
+	e.buf.WriteByte(charDoubleQuote)
+
+	e.writeEscapedString(value)
+	e.buf.WriteByte(charDoubleQuote)
+
+	e.child++
+}
+
+// UnsafeString will will marshal a string without escaping
+// Note: Only use this if you are CERTAIN that your value does not contain any quotes, backslashes or control characters
+func (e *Encoder) UnsafeString(key, value string) {
+	e.writeKey(key)
+	e.buf.WriteByte(charDoubleQuote)
+
+	e.buf.WriteString(value)
+	e.buf.WriteByte(charDoubleQuote)
+
+	e.child++
+}
 
+// Number will marshal a number
+func (e *Encoder) Number(key string, value float64) {
+	e.writeKey(key)
+	e.writeFloat(value, 64, -1)
+	e.child++
+}
+
+// Float32 will marshal a float32 using the shortest representation which round-trips as a float32
+func (e *Encoder) Float32(key string, value float32) {
+	e.writeKey(key)
+	e.writeFloat(float64(value), 32, -1)
+	e.child++
+}
+
+// Fixed will marshal a number with a fixed number of digits after the decimal point (e.g. 2 for currency)
+func (e *Encoder) Fixed(key string, value float64, precision int) {
+	e.writeKey(key)
+	e.writeFloat(value, 64, precision)
+	e.child++
+}
+
+// Int will marshal an int
+func (e *Encoder) Int(key string, value int) {
+	e.Int64(key, int64(value))
+}
+
+// Int64 will marshal an int64
+func (e *Encoder) Int64(key string, value int64) {
+	e.writeKey(key)
+	e.buf.WriteInt64(value)
+	e.child++
+}
+
+// Uint will marshal a uint
+func (e *Encoder) Uint(key string, value uint) {
+	e.Uint64(key, uint64(value))
+}
+
+// Uint64 will marshal a uint64
+func (e *Encoder) Uint64(key string, value uint64) {
+	e.writeKey(key)
+	e.buf.WriteUint64(value)
+	e.child++
+}
+
+// Null will marshal a null
+func (e *Encoder) Null(key string) {
+	e.writeKey(key)
+	e.buf.WriteNull()
+	e.child++
+}
+
+// Bool will marshal a boolean
+func (e *Encoder) Bool(key string, value bool) {
+	e.writeKey(key)
+	e.buf.WriteBool(value)
+	e.child++
+}
+
+// writeKey will write an object key (preceded by a separator when needed) and the following colon
+func (e *Encoder) writeKey(key string) {
+	e.writeSeparator()
+	e.buf.WriteByte(charDoubleQuote)
+	if e.unsafeKeys || isPlainASCII(key) {
+		e.buf.WriteString(key)
+	} else {
+		e.writeEscapedString(key)
+	}
+
+	e.buf.WriteString(`":`)
+	if e.indenting {
+		e.buf.WriteByte(charSpace)
+	}
+}
+
+// writeSeparator will write the comma preceding a value when needed, followed by a new line when indenting
+func (e *Encoder) writeSeparator() {
 	if e.child > 0 {
 		e.buf.WriteByte(charComma)
 	}
 
-	// Set child value to 0, since this is a new object
-	e.child = 0
-
-	e.buf.WriteByte(charDoubleQuote)
-	e.buf.WriteString(key)
-	e.buf.WriteString(`":{`)
+	if e.indenting {
+		e.writeNewline(e.depth)
+	}
+}
 
-	if err = value.MarshalJsoon(e); err != nil {
-		return
+// writeClose will write the closing character of an object or array, on a new line when indenting a non-empty value
+func (e *Encoder) writeClose(c byte) {
+	if e.indenting && e.child > 0 {
+		// Closing character is written at the parent's level
+		e.writeNewline(e.depth - 1)
 	}
 
-	e.buf.WriteByte(charCloseCurly)
+	e.buf.WriteByte(c)
+}
 
-	if _, err = e.w.Write(e.buf.Bytes()); err != nil {
+// writeNewline will write a new line followed by the prefix and the indent for the provided level
+func (e *Encoder) writeNewline(level int) {
+	e.buf.WriteByte(charNewline)
+	e.buf.WriteString(e.prefix)
+	for i := 0; i < level; i++ {
+		e.buf.WriteString(e.indent)
+	}
+}
+
+// encodeDocument will marshal either an object (obj) or an array (arr) as a document
+func (e *Encoder) encodeDocument(obj Encodee, arr ArrayEncodee) (err error) {
+	if err = e.openDocument(); err != nil {
 		return
 	}
 
-	e.buf.Reset()
+	// Ensure a failed document does not corrupt the encoder
+	defer e.rollback()
 
-	// Reduce depth to the parent's level
-	e.depth--
+	// Get parent's child value
+	pc := e.child
+
+	// Set child value to 0, since this is a new object or array
+	e.child = 0
+
+	if err = e.marshal(obj, arr); err != nil {
+		e.setErr(err)
+	}
 
 	// Set child value to parent's child value
 	e.child = pc
-	e.child++
 
-	return
+	return e.close()
 }
 
-// Array will marshal an array
-func (e *Encoder) Array(key string, value ArrayEncodee) (err error) {
-	if e.depth == 0 {
-		// Acquire buffer for this depth
-		e.buf = p.Acquire()
-	}
-
-	// Increase depth
-	e.depth++
-
+// encodeMember will marshal either an object (obj) or an array (arr) as a member of the current object or array, the
+// key or separator must already be written
+// Note: A failure is recorded as the sticky error, so the document fails even when the parent ignores the error
+func (e *Encoder) encodeMember(obj Encodee, arr ArrayEncodee) (err error) {
 	// Get parent's child value
 	pc := e.child
 
-	if pc > 0 {
-		e.buf.WriteByte(charComma)
+	if err = e.open(); err != nil {
+		return
 	}
 
-	// Set child value to 0, since this is a new object
+	// Set child value to 0, since this is a new object or array
 	e.child = 0
 
-	e.buf.WriteByte(charDoubleQuote)
-	e.buf.WriteString(key)
-	e.buf.WriteString(`":[`)
-	ae := p.AcquireAE(e)
+	if err = e.marshal(obj, arr); err != nil {
+		e.setErr(err)
+	}
+
+	// Set child value to parent's child value, accounting for this member
+	e.child = pc + 1
 
-	if err = value.MarshalJsoon(ae); err != nil {
+	return e.close()
+}
+
+// marshal will write the contents of either an object (obj) or an array (arr), including the enclosing characters
+func (e *Encoder) marshal(obj Encodee, arr ArrayEncodee) (err error) {
+	if obj != nil {
+		e.buf.WriteByte(charOpenCurly)
+		if err = obj.MarshalJsoon(e); err != nil {
+			return
+		}
+
+		e.writeClose(charCloseCurly)
 		return
 	}
 
+	e.buf.WriteByte(charOpenBracket)
+	ae := p.AcquireAE(e)
+	err = arr.MarshalJsoon(ae)
 	p.ReleaseAE(ae)
-	e.buf.WriteByte(charCloseBracket)
 
-	if _, err = e.w.Write(e.buf.Bytes()); err != nil {
+	if err != nil {
 		return
 	}
 
-	e.buf.Reset()
-
-	// Reduce depth to the parent's level
-	e.depth--
+	e.writeClose(charCloseBracket)
+	return
+}
 
-	// Set child value to parent's child value
-	e.child = pc
-	e.child++
+// open will increase the depth, acquiring a buffer when opening a new document
+func (e *Encoder) open() (err error) {
+	if e.depth == 0 {
+		// Acquire buffer for this depth
+		e.buf = p.Acquire()
+		// Clear the sticky error from any previous document
+		e.err = nil
+	} else if e.maxDepth > 0 && e.depth >= e.maxDepth {
+		e.setErr(ErrMaxDepth)
+		return e.err
+	}
 
+	// Increase depth
+	e.depth++
 	return
 }
 
-// String will escape and marshal a string
-func (e *Encoder) String(key, value string) {
-	if e.child > 0 {
-		e.buf.WriteByte(charComma)
+// openDocument will open a top-level value. Top-level values cannot be written while a document is being encoded
+// (e.g. from within MarshalJsoon) as they would be written without a key or separator, so an error is recorded instead
+func (e *Encoder) openDocument() (err error) {
+	if e.depth > 0 {
+		e.setErr(ErrDocumentInProgress)
+		return e.err
 	}
 
-	e.buf.WriteByte(charDoubleQuote)
-	e.buf.WriteString(key)
+	return e.open()
+}
 
-	e.buf.WriteString(`":"`)
+// close will reduce the depth to the parent's level. The document is written once the top-level value closes, or
+// earlier whenever the buffered bytes exceed the flush threshold
+func (e *Encoder) close() (err error) {
+	// Reduce depth to the parent's level
+	e.depth--
 
-	e.buf.WriteEscapedString(value)
-	e.buf.WriteByte(charDoubleQuote)
+	if err = e.err; err != nil {
+		if e.depth == 0 {
+			// Discard the invalid document
+			e.rollback()
+		}
 
-	e.child++
-}
-
-// UnsafeString will will marshal a string without escaping
-// Note: Only use this if you are CERTAIN that your value does not contain any quotes
-func (e *Encoder) UnsafeString(key, value string) {
-	if e.child > 0 {
-		e.buf.WriteByte(charComma)
+		return
 	}
 
-	e.buf.WriteByte(charDoubleQuote)
-	e.buf.WriteString(key)
+	if e.depth > 0 {
+		if e.flushThreshold > 0 && len(e.buf.Bytes()) >= e.flushThreshold {
+			err = e.flush()
+		}
 
-	e.buf.WriteString(`":"`)
+		return
+	}
 
-	e.buf.WriteString(value)
-	e.buf.WriteByte(charDoubleQuote)
+	if e.lineDelimited {
+		// Terminate the record so it is written along with the document
+		e.buf.WriteByte(charNewline)
+	}
 
-	e.child++
+	err = e.flush()
+	p.Release(e.buf)
+	e.buf = nil
+	return
 }
 
-// Number will marshal a number
-func (e *Encoder) Number(key string, value float64) {
-	if e.child > 0 {
-		e.buf.WriteByte(charComma)
+// flush will write the buffered bytes to the writer
+func (e *Encoder) flush() (err error) {
+	_, err = e.w.Write(e.buf.Bytes())
+	e.buf.Reset()
+	return
+}
+
+// rollback will discard a partially encoded document and restore the encoder to a clean state
+func (e *Encoder) rollback() {
+	if e.buf == nil {
+		// Document completed successfully, nothing to roll back
+		return
 	}
 
-	e.buf.WriteByte(charDoubleQuote)
-	e.buf.WriteString(key)
+	p.Release(e.buf)
+	e.buf = nil
+	e.depth = 0
+	e.child = 0
+}
 
-	e.buf.WriteString(`":`)
-	e.buf.WriteFloat64(value)
-	e.child++
+// setErr will set the sticky error, only the first error encountered is retained
+func (e *Encoder) setErr(err error) {
+	if e.err == nil {
+		e.err = err
+	}
 }
 
-// Bool will marshal a boolean
-func (e *Encoder) Bool(key string, value bool) {
-	if e.child > 0 {
-		e.buf.WriteByte(charComma)
+// writeEscapedString will escape and write a string, recording an error for invalid UTF-8 when strict
+func (e *Encoder) writeEscapedString(value string) {
+	if !e.buf.WriteEscapedString(value, e.escapeLineTerminators) && e.strictUTF8 {
+		e.setErr(ErrInvalidUTF8)
 	}
+}
 
-	e.buf.WriteByte(charDoubleQuote)
-	e.buf.WriteString(key)
+// writeFloat will write a float, applying the float policy to values which cannot be represented in JSON
+// Note: A negative precision writes the shortest representation which round-trips at the provided bit size
+func (e *Encoder) writeFloat(value float64, bitSize, precision int) {
+	if !math.IsNaN(value) && !math.IsInf(value, 0) {
+		if precision < 0 {
+			e.buf.WriteFloat(value, bitSize)
+		} else {
+			e.buf.WriteFixed(value, precision)
+		}
 
-	e.buf.WriteString(`":`)
-	e.buf.WriteBool(value)
-	e.child++
+		return
+	}
+
+	switch e.floatPolicy {
+	case FloatPolicyNull:
+		e.buf.WriteNull()
+	case FloatPolicyString:
+		e.buf.WriteByte(charDoubleQuote)
+		e.buf.WriteString(nonFiniteString(value))
+		e.buf.WriteByte(charDoubleQuote)
+	case FloatPolicyClamp:
+		e.buf.WriteFloat(clampFloat(value, bitSize), bitSize)
+	default:
+		e.setErr(ErrInvalidFloat)
+	}
 }
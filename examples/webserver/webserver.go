@@ -38,7 +38,7 @@ func (u *user) MarshalJsoon(enc *jsoon.Encoder) (err error) {
 	// We can lock from within the struct to ensure thread safety
 	u.mux.RLock()
 	enc.String("name", u.name)
-	enc.Number("age", float64(u.age))
+	enc.Int("age", u.age)
 	u.mux.RUnlock()
 	return
 }
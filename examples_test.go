@@ -24,11 +24,11 @@ type StripeChargeResponse struct {
 func (s *StripeChargeResponse) MarshalJsoon(e *Encoder) (err error) {
 	e.String("id", s.ID)
 	e.String("object", s.Object)
-	e.Number("amount", float64(s.Amount))
-	e.Number("amount_refunded", float64(s.AmountRefunded))
+	e.Int("amount", s.Amount)
+	e.Int("amount_refunded", s.AmountRefunded)
 	e.String("balance_transaction", s.BalanceTransaction)
 	e.Bool("captured", s.Captured)
-	e.Number("created", float64(s.Created))
+	e.Int("created", s.Created)
 	e.String("currency", s.Currency)
 	e.String("customer", s.Customer)
 	e.Bool("livemode", s.Livemode)
@@ -48,21 +48,15 @@ func (s *StripeChargeResponse) UnmarshalJsoon(key string, val *Value) (err error
 	case "object":
 		s.Object, err = val.String()
 	case "amount":
-		var a float64
-		a, err = val.Number()
-		s.Amount = int(a)
+		s.Amount, err = val.Int()
 	case "amount_refunded":
-		var ar float64
-		ar, err = val.Number()
-		s.AmountRefunded = int(ar)
+		s.AmountRefunded, err = val.Int()
 	case "balance_transaction":
 		s.BalanceTransaction, err = val.String()
 	case "captured":
 		s.Captured, err = val.Bool()
 	case "created":
-		var cr float64
-		cr, err = val.Number()
-		s.Created = int(cr)
+		s.Created, err = val.Int()
 	case "currency":
 		s.Currency, err = val.String()
 	case "customer":
@@ -70,6 +64,11 @@ func (s *StripeChargeResponse) UnmarshalJsoon(key string, val *Value) (err error
 	case "livemode":
 		s.Livemode, err = val.Bool()
 	case "outcome":
+		if val.IsNull() {
+			s.Outcome = nil
+			return
+		}
+
 		s.Outcome = &StripeOutcome{}
 		err = val.Object(s.Outcome)
 	case "paid":
@@ -77,9 +76,19 @@ func (s *StripeChargeResponse) UnmarshalJsoon(key string, val *Value) (err error
 	case "refunded":
 		s.Refunded, err = val.Bool()
 	case "refunds":
+		if val.IsNull() {
+			s.Refunds = nil
+			return
+		}
+
 		s.Refunds = &StripeRefunds{}
 		err = val.Object(s.Refunds)
 	case "source":
+		if val.IsNull() {
+			s.Source = nil
+			return
+		}
+
 		s.Source = &StripeSource{}
 		err = val.Object(s.Source)
 	case "status":
@@ -137,7 +146,7 @@ type StripeRefunds struct {
 func (s *StripeRefunds) MarshalJsoon(e *Encoder) (err error) {
 	e.String("object", s.Object)
 	e.Bool("has_more", s.HasMore)
-	e.Number("total_count", float64(s.TotalCount))
+	e.Int("total_count", s.TotalCount)
 	e.String("url", s.URL)
 	return
 }
@@ -149,9 +158,7 @@ func (s *StripeRefunds) UnmarshalJsoon(key string, val *Value) (err error) {
 	case "has_more":
 		s.HasMore, err = val.Bool()
 	case "total_count":
-		var tc float64
-		tc, err = val.Number()
-		s.TotalCount = int(tc)
+		s.TotalCount, err = val.Int()
 	case "url":
 		s.URL, err = val.String()
 	}
@@ -183,8 +190,8 @@ func (s *StripeSource) MarshalJsoon(e *Encoder) (err error) {
 	e.String("country", s.Country)
 	e.String("customer", s.Customer)
 	e.String("cvc_check", s.CVCCheck)
-	e.Number("exp_month", float64(s.ExpMonth))
-	e.Number("exp_year", float64(s.ExpYear))
+	e.Int("exp_month", s.ExpMonth)
+	e.Int("exp_year", s.ExpYear)
 	e.String("funding", s.Funding)
 	e.String("last4", s.Last4)
 	return
@@ -205,13 +212,9 @@ func (s *StripeSource) UnmarshalJsoon(key string, val *Value) (err error) {
 	case "cvc_check":
 		s.CVCCheck, err = val.String()
 	case "exp_month":
-		var em float64
-		em, err = val.Number()
-		s.ExpMonth = int(em)
+		s.ExpMonth, err = val.Int()
 	case "exp_year":
-		var ey float64
-		ey, err = val.Number()
-		s.ExpYear = int(ey)
+		s.ExpYear, err = val.Int()
 	case "funding":
 		s.Funding, err = val.String()
 	case "last4":
@@ -0,0 +1,156 @@
+package jsoon
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// FieldError is returned when a Decodee or ArrayDecodee returns an error for a value
+// Note: FieldError wraps the error returned by the Decodee or ArrayDecodee, so it can be matched using errors.Is
+type FieldError struct {
+	// Err is the underlying error
+	Err error
+	// Path is the JSON path of the value, such as refunds.data[3].amount
+	Path string
+	// Expected is the type the value was expected to be, this matches Actual when the error is not a type mismatch
+	Expected ValueType
+	// Actual is the type of the value
+	Actual ValueType
+}
+
+// Error will return the error message, including the path of the value
+func (e *FieldError) Error() string {
+	msg := e.Path + ": " + e.Err.Error()
+	if e.Expected != e.Actual {
+		msg += " (expected " + e.Expected.String() + ", found " + e.Actual.String() + ")"
+	}
+
+	return msg
+}
+
+// Unwrap will return the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// expectedType will return the value type implied by a Value accessor error
+func expectedType(err error) (vt ValueType, ok bool) {
+	switch err {
+	case ErrValueNotObject:
+		return TypeObject, true
+	case ErrValueNotArray:
+		return TypeArray, true
+	case ErrValueNotString, ErrValueNotBytes:
+		return TypeString, true
+	case ErrValueNotNumber, ErrValueNotInteger, ErrValueOverflow:
+		return TypeNumber, true
+	case ErrValueNotBool:
+		return TypeBool, true
+	}
+
+	return
+}
+
+// pathStack tracks the path to the value currently being decoded
+type pathStack struct {
+	// Bytes of every key within the path
+	keys []byte
+	// Elements of the path
+	elems []pathElement
+}
+
+// pathElement represents an object key or an array index within a path
+type pathElement struct {
+	// End of the element's key within the key bytes
+	end int
+	// Index within an array, -1 for object keys
+	index int
+}
+
+// pushKey will push an object key onto the path
+func (ps *pathStack) pushKey(key []byte) {
+	ps.keys = append(ps.keys, key...)
+	ps.elems = append(ps.elems, pathElement{end: len(ps.keys), index: -1})
+}
+
+// pushIndex will push an array index onto the path
+func (ps *pathStack) pushIndex(index int) {
+	ps.elems = append(ps.elems, pathElement{end: len(ps.keys), index: index})
+}
+
+// pop will pop the last element from the path
+func (ps *pathStack) pop() {
+	ps.elems = ps.elems[:len(ps.elems)-1]
+	if n := len(ps.elems); n > 0 {
+		ps.keys = ps.keys[:ps.elems[n-1].end]
+	} else {
+		ps.keys = ps.keys[:0]
+	}
+}
+
+// reset will clear the path
+func (ps *pathStack) reset() {
+	ps.keys = ps.keys[:0]
+	ps.elems = ps.elems[:0]
+}
+
+// String will return the path in dot notation, keys which cannot be represented this way are bracketed and quoted
+func (ps *pathStack) String() string {
+	var (
+		sb    strings.Builder
+		start int
+	)
+
+	for _, elem := range ps.elems {
+		if elem.index >= 0 {
+			sb.WriteByte(charOpenBracket)
+			sb.WriteString(strconv.Itoa(elem.index))
+			sb.WriteByte(charCloseBracket)
+			continue
+		}
+
+		key := string(ps.keys[start:elem.end])
+		start = elem.end
+
+		switch {
+		case len(key) == 0 || strings.ContainsAny(key, ".[]\""):
+			sb.WriteByte(charOpenBracket)
+			sb.WriteString(strconv.Quote(key))
+			sb.WriteByte(charCloseBracket)
+		case sb.Len() > 0:
+			sb.WriteByte(charPeriod)
+			fallthrough
+		default:
+			sb.WriteString(key)
+		}
+	}
+
+	return sb.String()
+}
+
+// fieldError will wrap an error returned by a Decodee or ArrayDecodee with the current path
+// Note: Errors which already carry context (field or syntax errors from nested values) are returned as-is
+func (ps *pathStack) fieldError(err error, actual ValueType) error {
+	var (
+		fe *FieldError
+		se *SyntaxError
+	)
+
+	if errors.As(err, &fe) || errors.As(err, &se) {
+		return err
+	}
+
+	e := FieldError{
+		Err:    err,
+		Path:   ps.String(),
+		Actual: actual,
+	}
+
+	var ok bool
+	if e.Expected, ok = expectedType(err); !ok {
+		e.Expected = actual
+	}
+
+	return &e
+}
@@ -0,0 +1,144 @@
+package jsoon
+
+import "bytes"
+
+// Valid will return whether or not data is valid JSON
+func Valid(data []byte) bool {
+	return validate(data) == nil
+}
+
+// Compact will append src to dst with all insignificant whitespace removed
+// Note: If src is not valid JSON, an error is returned and dst is left unchanged
+func Compact(dst *bytes.Buffer, src []byte) (err error) {
+	if err = validate(src); err != nil {
+		return
+	}
+
+	var (
+		// Start of the current run of significant bytes
+		start int
+		// Whether or not we are currently within a string
+		inString bool
+		// Whether or not the previous character was an escaping backslash
+		escaped bool
+	)
+
+	for i, b := range src {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == charBackslash:
+				escaped = true
+			case b == charDoubleQuote:
+				inString = false
+			}
+
+			continue
+		}
+
+		switch {
+		case b == charDoubleQuote:
+			inString = true
+		case isWhitespace(b):
+			dst.Write(src[start:i])
+			start = i + 1
+		}
+	}
+
+	dst.Write(src[start:])
+	return
+}
+
+// Indent will append an indented form of src to dst. Each element of an object or array begins on a new line starting
+// with prefix, followed by one copy of indent per level of nesting. Empty objects and arrays remain compact
+// Note: If src is not valid JSON, an error is returned and dst is left unchanged
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) (err error) {
+	if err = validate(src); err != nil {
+		return
+	}
+
+	var (
+		// Current nesting depth
+		depth int
+		// Whether or not we are currently within a string
+		inString bool
+		// Whether or not the previous character was an escaping backslash
+		escaped bool
+		// Whether or not an object or array was just opened
+		opened bool
+	)
+
+	for _, b := range src {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == charBackslash:
+				escaped = true
+			case b == charDoubleQuote:
+				inString = false
+			}
+
+			dst.WriteByte(b)
+			continue
+		}
+
+		if isWhitespace(b) {
+			continue
+		}
+
+		if opened {
+			opened = false
+			if b != charCloseCurly && b != charCloseBracket {
+				// Non-empty object or array, the first element begins on a new line
+				depth++
+				writeIndent(dst, prefix, indent, depth)
+			} else {
+				// Empty object or array, remain compact
+				dst.WriteByte(b)
+				continue
+			}
+		}
+
+		switch b {
+		case charDoubleQuote:
+			inString = true
+			dst.WriteByte(b)
+		case charOpenCurly, charOpenBracket:
+			opened = true
+			dst.WriteByte(b)
+		case charComma:
+			dst.WriteByte(b)
+			writeIndent(dst, prefix, indent, depth)
+		case charColon:
+			dst.WriteByte(b)
+			dst.WriteByte(charSpace)
+		case charCloseCurly, charCloseBracket:
+			depth--
+			writeIndent(dst, prefix, indent, depth)
+			dst.WriteByte(b)
+		default:
+			dst.WriteByte(b)
+		}
+	}
+
+	return
+}
+
+// validate will validate data using a pooled scanner
+func validate(data []byte) (err error) {
+	s := p.AcquireScanner(data)
+	err = s.Validate()
+	p.ReleaseScanner(s)
+	return
+}
+
+// writeIndent will write a new line followed by the prefix and the indent for the provided depth
+func writeIndent(dst *bytes.Buffer, prefix, indent string, depth int) {
+	dst.WriteByte(charNewline)
+	dst.WriteString(prefix)
+	for i := 0; i < depth; i++ {
+		dst.WriteString(indent)
+	}
+}
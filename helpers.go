@@ -2,6 +2,10 @@ package jsoon
 
 import (
 	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -9,6 +13,9 @@ var (
 	trueBytes  = [4]byte{'t', 'r', 'u', 'e'}
 	falseBytes = [5]byte{'f', 'a', 'l', 's', 'e'}
 	nullBytes  = [4]byte{'n', 'u', 'l', 'l'}
+
+	nanBytes      = [3]byte{'N', 'a', 'N'}
+	infinityBytes = [8]byte{'I', 'n', 'f', 'i', 'n', 'i', 't', 'y'}
 )
 
 func isTrueBytes(s []byte) bool {
@@ -36,9 +43,132 @@ func isNumber(b byte) bool {
 }
 
 func isWhitespace(b byte) bool {
-	return b == charSpace || b == charTab || b == charNewline
+	return b == charSpace || b == charTab || b == charNewline || b == charCarriageReturn
+}
+
+// parseUint will parse an unsigned integer which cannot exceed max
+func parseUint(s []byte, max uint64) (n uint64, err error) {
+	var overflow bool
+	if len(s) == 0 {
+		return 0, ErrValueNotInteger
+	}
+
+	for _, b := range s {
+		if !isNumber(b) {
+			// Fractions and exponents cannot be represented as an integer
+			return 0, ErrValueNotInteger
+		}
+
+		if overflow {
+			// Continue iterating so non-integer values are reported over overflows
+			continue
+		}
+
+		d := uint64(b - charZero)
+		if n > max/10 || (n == max/10 && d > max%10) {
+			overflow = true
+			continue
+		}
+
+		n = n*10 + d
+	}
+
+	if overflow {
+		return 0, ErrValueOverflow
+	}
+
+	return
+}
+
+// parseInt will parse a signed integer which fits within the provided bit size
+func parseInt(s []byte, bitSize uint) (n int64, err error) {
+	var u uint64
+	if len(s) > 0 && s[0] == charHyphen {
+		if u, err = parseUint(s[1:], 1<<(bitSize-1)); err != nil {
+			return
+		}
+
+		return -int64(u), nil
+	}
+
+	if u, err = parseUint(s, 1<<(bitSize-1)-1); err != nil {
+		return
+	}
+
+	return int64(u), nil
+}
+
+// nonFiniteString will return the string representation of a NaN or infinite float
+func nonFiniteString(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case v > 0:
+		return "Infinity"
+	default:
+		return "-Infinity"
+	}
+}
+
+// clampFloat will clamp a NaN or infinite float to a finite value of the provided bit size
+func clampFloat(v float64, bitSize int) float64 {
+	max := math.MaxFloat64
+	if bitSize == 32 {
+		max = math.MaxFloat32
+	}
+
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v > 0:
+		return max
+	default:
+		return -max
+	}
+}
+
+// unexpectedEnd will convert an io.EOF encountered mid-value to ErrUnexpectedEnd
+func unexpectedEnd(err error) error {
+	if err == io.EOF {
+		return ErrUnexpectedEnd
+	}
+
+	return err
+}
+
+// isNil will return whether or not a value is a nil interface or an interface holding a nil pointer
+// Note: Other nil-able kinds (such as maps and funcs) are not considered nil, as their MarshalJsoon may still be called
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
 }
 
 func unsafeString(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b))
 }
+
+// sliceWriter is an io.Writer which appends to a byte slice
+type sliceWriter struct {
+	s []byte
+}
+
+func (w *sliceWriter) Write(v []byte) (n int, err error) {
+	w.s = append(w.s, v...)
+	return len(v), nil
+}
+
+// isPlainASCII will return whether or not a string consists only of ASCII characters which do not require escaping
+// Note: This is used as a fast path for keys, which are typically short compile-time constants
+func isPlainASCII(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; c < charSpace || c >= utf8.RuneSelf || c == charDoubleQuote || c == charBackslash {
+			return false
+		}
+	}
+
+	return true
+}
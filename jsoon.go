@@ -9,6 +9,19 @@ var (
 	ErrUnexpectedEnd = errors.New("input ended before expected")
 	//ErrInvalidValue is returned when a Decodee is not present for an object or a ArrayDecodee is not present for an array
 	ErrInvalidValue = errors.New("invalid value provided")
+	// ErrInvalidNumber is returned when a number does not follow the JSON number grammar
+	ErrInvalidNumber = errors.New("invalid number")
+	// ErrInvalidEscape is returned when a string contains an invalid escape sequence
+	ErrInvalidEscape = errors.New("invalid escape sequence")
+
+	// ErrInvalidFloat is returned when encoding a NaN or infinite float, which cannot be represented in JSON
+	ErrInvalidFloat = errors.New("NaN and infinite floats cannot be represented in json")
+	// ErrInvalidUTF8 is returned when encoding a string containing invalid UTF-8 while strict UTF-8 is enabled
+	ErrInvalidUTF8 = errors.New("string contains invalid UTF-8")
+	// ErrMaxDepth is returned when encoding exceeds the maximum depth, or when decoding exceeds the maximum nesting depth
+	ErrMaxDepth = errors.New("maximum depth exceeded")
+	// ErrDocumentInProgress is returned when a top-level Encode method is called while a document is being encoded
+	ErrDocumentInProgress = errors.New("cannot encode a top-level value while a document is being encoded")
 
 	// ErrValueNotObject is returned when value is not an object
 	ErrValueNotObject = errors.New("value cannot be parsed as an object")
@@ -20,19 +33,52 @@ var (
 	ErrValueNotBytes = errors.New("value cannot be parsed as bytes")
 	// ErrValueNotNumber is returned when value is not a number
 	ErrValueNotNumber = errors.New("value cannot be parsed as a number")
+	// ErrValueNotInteger is returned when a number value contains a fraction or an exponent
+	ErrValueNotInteger = errors.New("value cannot be parsed as an integer")
+	// ErrValueOverflow is returned when a number value does not fit within the requested integer type
+	ErrValueOverflow = errors.New("value overflows the requested integer type")
 	// ErrValueNotBool is returned when value is not a boolean
 	ErrValueNotBool = errors.New("value cannot be parsed as a boolean")
 )
 
+// ValueType represents the type of a JSON value
+type ValueType uint8
+
 const (
-	valNil uint8 = iota
-	valObject
-	valArray
-	valString
-	valNumber
-	valBool
+	// TypeNull represents a null value
+	TypeNull ValueType = iota
+	// TypeObject represents an object value
+	TypeObject
+	// TypeArray represents an array value
+	TypeArray
+	// TypeString represents a string value
+	TypeString
+	// TypeNumber represents a number value
+	TypeNumber
+	// TypeBool represents a boolean value
+	TypeBool
 )
 
+// String will return the name of a value type
+func (vt ValueType) String() string {
+	switch vt {
+	case TypeNull:
+		return "null"
+	case TypeObject:
+		return "object"
+	case TypeArray:
+		return "array"
+	case TypeString:
+		return "string"
+	case TypeNumber:
+		return "number"
+	case TypeBool:
+		return "boolean"
+	}
+
+	return "unknown"
+}
+
 var p = newPool()
 
 // Encodee is an item that has a Marshal helper func
@@ -61,3 +107,62 @@ type ReadByter interface {
 	ReadByte() (byte, error)
 	UnreadByte() error
 }
+
+// Marshal will marshal an Encodee and return the resulting bytes
+func Marshal(value Encodee) ([]byte, error) {
+	return AppendMarshal(nil, value)
+}
+
+// AppendMarshal will marshal an Encodee and append the result to dst
+func AppendMarshal(dst []byte, value Encodee) ([]byte, error) {
+	w := sliceWriter{s: dst}
+	if err := NewEncoder(&w).Encode(value); err != nil {
+		return dst, err
+	}
+
+	return w.s, nil
+}
+
+// MarshalArray will marshal an ArrayEncodee and return the resulting bytes
+func MarshalArray(value ArrayEncodee) ([]byte, error) {
+	var w sliceWriter
+	if err := NewEncoder(&w).EncodeArray(value); err != nil {
+		return nil, err
+	}
+
+	return w.s, nil
+}
+
+// UnmarshalOptions configures Unmarshal and UnmarshalArray, it mirrors the options available on Decoder
+type UnmarshalOptions struct {
+	// AllowNaN accepts the non-standard NaN, Infinity and -Infinity literals as numbers, see Decoder.SetAllowNaN
+	AllowNaN bool
+}
+
+// Unmarshal will unmarshal a json object into a Decodee
+func Unmarshal(data []byte, value Decodee) (err error) {
+	return UnmarshalWith(data, value, UnmarshalOptions{})
+}
+
+// UnmarshalWith will unmarshal a json object into a Decodee using the provided options
+func UnmarshalWith(data []byte, value Decodee, opts UnmarshalOptions) (err error) {
+	s := p.AcquireScanner(data)
+	s.allowNaN = opts.AllowNaN
+	err = s.Decode(value)
+	p.ReleaseScanner(s)
+	return
+}
+
+// UnmarshalArray will unmarshal a json array into an ArrayDecodee
+func UnmarshalArray(data []byte, value ArrayDecodee) (err error) {
+	return UnmarshalArrayWith(data, value, UnmarshalOptions{})
+}
+
+// UnmarshalArrayWith will unmarshal a json array into an ArrayDecodee using the provided options
+func UnmarshalArrayWith(data []byte, value ArrayDecodee, opts UnmarshalOptions) (err error) {
+	s := p.AcquireScanner(data)
+	s.allowNaN = opts.AllowNaN
+	err = s.Decode(value)
+	p.ReleaseScanner(s)
+	return
+}
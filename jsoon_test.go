@@ -1,11 +1,18 @@
 package jsoon
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/buger/jsonparser"
 )
@@ -107,6 +114,1565 @@ func TestStripeUnmarshal(t *testing.T) {
 	}
 }
 
+func TestUnmarshalNumber(t *testing.T) {
+	valid := []struct {
+		src string
+		val float64
+	}{
+		{"0", 0},
+		{"-0", 0},
+		{"12", 12},
+		{"12.5", 12.5},
+		{"-0.25", -0.25},
+		{"1e9", 1e9},
+		{"1E+2", 100},
+		{"-0.25E-3", -0.00025},
+		{"6.02e23", 6.02e23},
+	}
+
+	for _, tc := range valid {
+		var ns numberStruct
+		src := `{"value":` + tc.src + `,"after":true}`
+		if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ns); err != nil {
+			t.Fatalf("error decoding %s: %v", tc.src, err)
+		}
+
+		if ns.Value != tc.val {
+			t.Fatalf("invalid value for %s, expected %v and received %v", tc.src, tc.val, ns.Value)
+		}
+
+		if !ns.After {
+			t.Fatalf("value following %s was not decoded", tc.src)
+		}
+	}
+
+	invalid := []struct {
+		src string
+		err error
+	}{
+		{"-", ErrInvalidNumber},
+		{"01", ErrInvalidNumber},
+		{"-01", ErrInvalidNumber},
+		{"1.", ErrInvalidNumber},
+		{"1.e5", ErrInvalidNumber},
+		{"1e", ErrInvalidNumber},
+		{"1e+", ErrInvalidNumber},
+		{"1.5.5", ErrInvalidNumber},
+		{"12a", ErrInvalidNumber},
+		{"--1", ErrInvalidNumber},
+		// Values which do not begin like a number are not numbers at all
+		{".5", ErrInvalidChar},
+		{"+1", ErrInvalidChar},
+	}
+
+	for _, tc := range invalid {
+		var ns numberStruct
+		err := NewDecoder(bytes.NewReader([]byte(`{"value":` + tc.src + `}`))).Decode(&ns)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("invalid error for %s, expected %v and received %v", tc.src, tc.err, err)
+		}
+
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Fatalf("expected a positioned error for %s, received %v", tc.src, err)
+		}
+	}
+}
+
+func TestUnmarshalInteger(t *testing.T) {
+	var is intStruct
+	src := `{"int64":9007199254740993,"uint64":18446744073709551615,"int32":-2147483648,"negative":-12}`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&is); err != nil {
+		t.Fatal(err)
+	}
+
+	if is.Int64 != 9007199254740993 {
+		t.Fatalf("invalid int64, expected %d and received %d", int64(9007199254740993), is.Int64)
+	}
+
+	if is.Uint64 != 18446744073709551615 {
+		t.Fatalf("invalid uint64, expected %d and received %d", uint64(18446744073709551615), is.Uint64)
+	}
+
+	if is.Int32 != -2147483648 {
+		t.Fatalf("invalid int32, expected %d and received %d", -2147483648, is.Int32)
+	}
+
+	if is.Negative != -12 {
+		t.Fatalf("invalid int, expected %d and received %d", -12, is.Negative)
+	}
+
+	errs := []struct {
+		src string
+		err error
+	}{
+		{`{"int32":2147483648}`, ErrValueOverflow},
+		{`{"int64":-9223372036854775809}`, ErrValueOverflow},
+		{`{"uint64":18446744073709551616}`, ErrValueOverflow},
+		{`{"uint64":-1}`, ErrValueOverflow},
+		{`{"int64":12.5}`, ErrValueNotInteger},
+		{`{"int64":1e3}`, ErrValueNotInteger},
+		{`{"int64":"12"}`, ErrValueNotNumber},
+	}
+
+	for _, tc := range errs {
+		if err := NewDecoder(bytes.NewReader([]byte(tc.src))).Decode(&is); !errors.Is(err, tc.err) {
+			t.Fatalf("invalid error for %s, expected %v and received %v", tc.src, tc.err, err)
+		}
+	}
+}
+
+func TestMarshalInteger(t *testing.T) {
+	is := intStruct{
+		Int64:    9007199254740993,
+		Uint64:   18446744073709551615,
+		Int32:    -2147483648,
+		Negative: -12,
+		Values:   intSlice{-9223372036854775808, 0, 42},
+	}
+
+	expected := `{"int64":9007199254740993,"uint64":18446744073709551615,"int32":-2147483648,"negative":-12,"values":[-9223372036854775808,0,42]}`
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(&is); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+}
+
+func TestMarshalEscapedString(t *testing.T) {
+	values := []string{
+		"clean string",
+		`quote " and backslash \`,
+		"newline \n carriage return \r tab \t",
+		"backspace \b form feed \f null \x00 unit separator \x1f",
+		"unicode é 世界 \U0001F600",
+		"separators \u2028 \u2029",
+	}
+
+	for _, v := range values {
+		buf := bytes.NewBuffer(nil)
+		if err := NewEncoder(buf).Encode(&stringStruct{Value: v}); err != nil {
+			t.Fatal(err)
+		}
+
+		var out map[string]string
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("invalid json produced for %q: %v (%s)", v, err, buf.String())
+		}
+
+		if out["value"] != v {
+			t.Fatalf("invalid value, expected %q and received %q", v, out["value"])
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	enc := NewEncoder(buf)
+	enc.SetEscapeLineTerminators(true)
+	if err := enc.Encode(&stringStruct{Value: "a\u2028b\u2029c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"value":"a\u2028b\u2029c"}`; buf.String() != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(&stringStruct{Value: "bad \xff utf8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"value":"bad \ufffd utf8"}`; buf.String() != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, buf.String())
+	}
+}
+
+func TestUnmarshalEscapedString(t *testing.T) {
+	valid := []struct {
+		src string
+		val string
+	}{
+		{`"plain"`, "plain"},
+		{`"quote \" backslash \\ slash \/"`, `quote " backslash \ slash /`},
+		{`"\b\f\n\r\t"`, "\b\f\n\r\t"},
+		{`"caf\u00e9 \u00E9"`, "café é"},
+		{`"smile \ud83d\ude00"`, "smile \U0001F600"},
+		{`"lone \ud83d end"`, "lone \uFFFD end"},
+		{`"lone \ude00 end"`, "lone \uFFFD end"},
+		{`"pair \ud83d\n"`, "pair \uFFFD\n"},
+		{`"pair \ud83d\u0041"`, "pair \uFFFDA"},
+	}
+
+	for _, tc := range valid {
+		var ss stringStruct
+		if err := NewDecoder(bytes.NewReader([]byte(`{"value":` + tc.src + `}`))).Decode(&ss); err != nil {
+			t.Fatalf("error decoding %s: %v", tc.src, err)
+		}
+
+		if ss.Value != tc.val {
+			t.Fatalf("invalid value for %s, expected %q and received %q", tc.src, tc.val, ss.Value)
+		}
+	}
+
+	invalid := []string{`"\x"`, `"\u12"`, `"\u12g4"`, `"\ud83d\q"`}
+	for _, src := range invalid {
+		var ss stringStruct
+		if err := NewDecoder(bytes.NewReader([]byte(`{"value":` + src + `}`))).Decode(&ss); !errors.Is(err, ErrInvalidEscape) {
+			t.Fatalf("expected %v for %s and received %v", ErrInvalidEscape, src, err)
+		}
+	}
+}
+
+func TestUnmarshalSkip(t *testing.T) {
+	var ss stringStruct
+	src := `{"ignored":{"nested":[1,{"a":"}]"}],"quote":"\"{["},"ignoredArray":[[],{}, "]"],"value":"found","empty":{}}`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if ss.Value != "found" {
+		t.Fatalf("invalid value, expected %q and received %q", "found", ss.Value)
+	}
+
+	var ts testSimpleStructSlice
+	src = `[{"dateCreated":"2017-01-01","ignored":{"a":[1,2]},"lastLogin":"2017-01-02"}]`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ts) != 1 || ts[0].LastLogin != "2017-01-02" {
+		t.Fatalf("invalid value, received %+v", ts)
+	}
+
+	var sk skipStruct
+	src = `{"skipped":{"a":1},"value":"found"}`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&sk); err != nil {
+		t.Fatal(err)
+	}
+
+	if sk.Value != "found" {
+		t.Fatalf("invalid value, expected %q and received %q", "found", sk.Value)
+	}
+
+	if err := NewDecoder(bytes.NewReader([]byte(`{"ignored":{"a":[1,2}`))).Decode(&ss); !errors.Is(err, ErrUnexpectedEnd) {
+		t.Fatalf("expected %v and received %v", ErrUnexpectedEnd, err)
+	}
+}
+
+func TestMarshalEscapedKey(t *testing.T) {
+	// Keys which are not plain ASCII are escaped through the slow path
+	ms := mapStruct{{`say "hi"`, "a"}, {"line\nbreak", "b"}, {`back\slash`, "c"}, {"caf\u00e9", "d"}, {"bad\xff", "e"}}
+	expected := `{"say \"hi\"":"a","line\nbreak":"b","back\\slash":"c","café":"d","bad\ufffd":"e"}`
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(ms); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+
+	ts := newTestStruct()
+	buf.Reset()
+	enc := NewEncoder(buf)
+	enc.SetUnsafeKeys(true)
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+}
+
+func TestUnmarshalGrammar(t *testing.T) {
+	var ts testStruct
+	src := `{ "\u006eame" : "Escaped" , "additional" : { } , "additionals" : [ ] }`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if ts.Name != "Escaped" {
+		t.Fatalf("invalid name, expected %q and received %q", "Escaped", ts.Name)
+	}
+
+	if ts.Additional == nil || len(ts.Additionals) != 0 {
+		t.Fatalf("expected an empty object and an empty array, received %v and %v", ts.Additional, ts.Additionals)
+	}
+
+	var ss testSimpleStructSlice
+	if err := NewDecoder(bytes.NewReader([]byte(`[ {"dateCreated":"a"} , {} ]`))).Decode(&ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ss) != 2 || ss[0].DateCreated != "a" {
+		t.Fatalf("invalid array, received %v", ss)
+	}
+
+	if err := NewDecoder(bytes.NewReader([]byte(`[]`))).Decode(&ts); err != ErrInvalidValue {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidValue, err)
+	}
+}
+
+func TestUnmarshalWhitespace(t *testing.T) {
+	src := strings.Replace(testExpanded, "\n", "\r\n", -1)
+	for _, src := range []string{src, "{\"name\":\"Test Name\",\r\n\"age\":32\r\n}"} {
+		var ts testStruct
+		if err := NewDecoder(strings.NewReader(src)).Decode(&ts); err != nil {
+			t.Fatal(err)
+		}
+
+		if ts.Name != "Test Name" || ts.Age != 32 {
+			t.Fatalf("invalid result for %q, received %q and %v", src, ts.Name, ts.Age)
+		}
+	}
+
+	var ss stringStruct
+	if err := NewDecoder(strings.NewReader("{\"value\":\"raw\ttab\"}")).Decode(&ss); !errors.Is(err, ErrInvalidChar) {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidChar, err)
+	}
+}
+
+func TestMarshalArrayString(t *testing.T) {
+	ss := stringSlice{"plain", `say "hi"`, "tab\t"}
+	expected := `{"values":["plain","say \"hi\"","tab\t"],"unsafe":["plain","say "hi"","tab` + "\t" + `"]}`
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(&ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+}
+
+func TestMarshalNull(t *testing.T) {
+	sr := StripeChargeResponse{ID: "ch_1", Status: "succeeded"}
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(&sr); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"id":"ch_1","object":"","amount":0,"amount_refunded":0,"balance_transaction":"","captured":false,"created":0,"currency":"","customer":"","livemode":false,"outcome":null,"paid":false,"refunded":false,"refunds":null,"source":null,"status":"succeeded"}`
+	if str := buf.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+
+	ns := nullStruct{Additionals: testSimpleStructSlice{nil, {"2017-01-01", "2017-01-02"}}}
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(&ns); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `{"null":null,"additional":null,"nilInterface":null,"additionals":[null,{"dateCreated":"2017-01-01","lastLogin":"2017-01-02"}],"values":null}`
+	if str := buf.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+
+	// Values which merely hold a nil pointer or a nil map are not nil, so they must be marshaled
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(ptrWrap{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != `{"set":false}` {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", `{"set":false}`, buf.String())
+	}
+
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(nilMap(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != `{"len":0}` {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", `{"len":0}`, buf.String())
+	}
+
+	var (
+		nilPtr   *testSimpleStruct
+		nilIface Encodee
+	)
+
+	cases := []struct {
+		v     interface{}
+		isNil bool
+	}{
+		{nil, true},
+		{nilIface, true},
+		{nilPtr, true},
+		{Encodee(nilPtr), true},
+		{&testSimpleStruct{}, false},
+		{ptrWrap{}, false},
+		{nilMap(nil), false},
+		{testSimpleStructSlice(nil), false},
+	}
+
+	for i, tc := range cases {
+		if isNil(tc.v) != tc.isNil {
+			t.Fatalf("invalid isNil result for case %d (%T), expected %v", i, tc.v, tc.isNil)
+		}
+	}
+}
+
+func TestValueType(t *testing.T) {
+	var ts typeStruct
+	src := `{"null":null,"object":{"a":1},"array":[1],"string":"a","number":1.5,"bool":false}`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]ValueType{
+		"null":   TypeNull,
+		"object": TypeObject,
+		"array":  TypeArray,
+		"string": TypeString,
+		"number": TypeNumber,
+		"bool":   TypeBool,
+	}
+
+	for key, vt := range expected {
+		if ts.types[key] != vt {
+			t.Fatalf("invalid type for %s, expected %v and received %v", key, vt, ts.types[key])
+		}
+	}
+
+	if !ts.null {
+		t.Fatal("expected null value to report IsNull")
+	}
+
+	var ns nullableStruct
+	ns.String = "untouched"
+	ns.Number = 12
+	src = `{"string":null,"bytes":null,"number":null,"int":null,"uint":null,"bool":null,"object":null,"array":null}`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ns); err != nil {
+		t.Fatal(err)
+	}
+
+	if ns.String != "" || ns.Bytes != nil || ns.Number != 0 || ns.Int != 0 || ns.Uint != 0 || ns.Bool {
+		t.Fatalf("expected zero values for null, received %+v", ns)
+	}
+
+	if ns.Object != nil {
+		t.Fatalf("expected null object to be left untouched, received %+v", ns.Object)
+	}
+}
+
+func TestMarshalTopLevel(t *testing.T) {
+	var nilStruct *testStruct
+	ts := newTestStruct()
+	buf := bytes.NewBuffer(nil)
+	enc := NewEncoder(buf)
+
+	tests := []struct {
+		fn       func() error
+		expected string
+	}{
+		{func() error { return enc.EncodeArray(ts.Additionals) }, `[{"dateCreated":"2017-01-01","lastLogin":"2017-01-01"},{"dateCreated":"2017-01-02","lastLogin":"2017-01-02"},{"dateCreated":"2017-01-03","lastLogin":"2017-01-03"}]`},
+		{func() error { return enc.EncodeArray(intSlice{}) }, `[]`},
+		{func() error { return enc.EncodeString(`say "hi"`) }, `"say \"hi\""`},
+		{func() error { return enc.EncodeNumber(12.5) }, `12.5`},
+		{func() error { return enc.EncodeInt64(-9223372036854775808) }, `-9223372036854775808`},
+		{func() error { return enc.EncodeUint64(18446744073709551615) }, `18446744073709551615`},
+		{func() error { return enc.EncodeBool(true) }, `true`},
+		{func() error { return enc.EncodeNull() }, `null`},
+		{func() error { return enc.Encode(nilStruct) }, `null`},
+		{func() error { return enc.Encode(&ts) }, testStr},
+	}
+
+	for _, tc := range tests {
+		buf.Reset()
+		if err := tc.fn(); err != nil {
+			t.Fatal(err)
+		}
+
+		if str := buf.String(); str != tc.expected {
+			t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", tc.expected, str)
+		}
+	}
+
+	// Top-level values cannot be written while a document is being encoded
+	fns := []func(enc *Encoder) error{
+		func(enc *Encoder) error { return enc.EncodeString("oops") },
+		func(enc *Encoder) error { return enc.EncodeNumber(1) },
+		func(enc *Encoder) error { return enc.EncodeInt64(1) },
+		func(enc *Encoder) error { return enc.EncodeUint64(1) },
+		func(enc *Encoder) error { return enc.EncodeBool(true) },
+		func(enc *Encoder) error { return enc.EncodeNull() },
+		func(enc *Encoder) error { return enc.Encode(&stringStruct{}) },
+		func(enc *Encoder) error { return enc.EncodeArray(intSlice{}) },
+	}
+
+	for _, fn := range fns {
+		buf.Reset()
+		ns := nestedEncodeStruct{fn: fn}
+		if err := enc.Encode(&ns); err != ErrDocumentInProgress {
+			t.Fatalf("expected %v and received %v", ErrDocumentInProgress, err)
+		}
+
+		if ns.err != ErrDocumentInProgress {
+			t.Fatalf("expected the nested call to return %v, received %v", ErrDocumentInProgress, ns.err)
+		}
+
+		if buf.Len() != 0 {
+			t.Fatalf("expected the invalid document to be discarded, received %s", buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+}
+
+func TestMarshalBytes(t *testing.T) {
+	ts := newTestStruct()
+	bs, err := Marshal(&ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if str := string(bs); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+
+	if bs, err = AppendMarshal([]byte("prefix:"), &ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := string(bs); str != "prefix:"+testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", "prefix:"+testStr, str)
+	}
+
+	if bs, err = MarshalArray(intSlice{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := string(bs); str != "[1,2,3]" {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", "[1,2,3]", str)
+	}
+}
+
+func TestUnmarshalBytes(t *testing.T) {
+	var ts testStruct
+	cts := newTestStruct()
+	for _, src := range []string{testStr, testExpanded} {
+		if err := Unmarshal([]byte(src), &ts); err != nil {
+			t.Fatal(err)
+		}
+
+		if !ts.Equals(&cts) {
+			t.Fatalf("invalid value, expected <%+v> and received <%+v>", cts, ts)
+		}
+	}
+
+	var tss testSimpleStructSlice
+	if err := UnmarshalArray([]byte(` [{"dateCreated":"a","lastLogin":"b"} , {"lastLogin":"\u00e9"}] `), &tss); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tss) != 2 || tss[0].DateCreated != "a" || tss[1].LastLogin != "é" {
+		t.Fatalf("invalid value, received %+v", tss)
+	}
+
+	var ss stringStruct
+	src := `{"ignored":{"a":["}",{"b":"\""}]},"empty":{},"emptyArray":[],"value":"caf\u00e9\n"}`
+	for _, dec := range []func(Decodee) error{
+		func(v Decodee) error { return Unmarshal([]byte(src), v) },
+		func(v Decodee) error { return NewDecoder(bytes.NewReader([]byte(src))).Decode(v) },
+	} {
+		if err := dec(&ss); err != nil {
+			t.Fatal(err)
+		}
+
+		if ss.Value != "café\n" {
+			t.Fatalf("invalid value, expected %q and received %q", "café\n", ss.Value)
+		}
+	}
+
+	var is intStruct
+	if err := Unmarshal([]byte(`{"int64":-12,"uint64":1e3}`), &is); !errors.Is(err, ErrValueNotInteger) {
+		t.Fatalf("expected %v and received %v", ErrValueNotInteger, err)
+	}
+
+	errs := []struct {
+		src string
+		err error
+	}{
+		{``, ErrUnexpectedEnd},
+		{`{"value":"a"`, ErrUnexpectedEnd},
+		{`{"value":"a}`, ErrUnexpectedEnd},
+		{`{"value":"a"} trailing`, ErrInvalidChar},
+		{`{"value":"a",}`, ErrInvalidChar},
+		{`{"value":01}`, ErrInvalidNumber},
+		{`{"value":tru}`, ErrInvalidChar},
+		{`{"value":"\q"}`, ErrInvalidEscape},
+		{`[1]`, ErrInvalidValue},
+	}
+
+	for _, tc := range errs {
+		if err := Unmarshal([]byte(tc.src), &ss); !errors.Is(err, tc.err) {
+			t.Fatalf("invalid error for %s, expected %v and received %v", tc.src, tc.err, err)
+		}
+	}
+}
+
+func TestUnmarshalZeroCopy(t *testing.T) {
+	var bs bytesStruct
+	src := []byte(`{"plain":"abc","escaped":"a\nc","number":12.5}`)
+	if err := Unmarshal(src, &bs); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bs.plainShared {
+		t.Fatal("expected unescaped string to reference the source data")
+	}
+
+	if bs.escapedShared {
+		t.Fatal("expected escaped string to be copied from the source data")
+	}
+
+	if bs.escaped != "a\nc" {
+		t.Fatalf("invalid value, expected %q and received %q", "a\nc", bs.escaped)
+	}
+
+	if !bs.numberShared {
+		t.Fatal("expected number to reference the source data")
+	}
+
+	if raceEnabled {
+		t.Skip("allocations are not counted when the race detector is enabled")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := Unmarshal(src, &bs); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Fatalf("expected no allocations and received %v", allocs)
+	}
+}
+
+func TestMarshalSingleWrite(t *testing.T) {
+	ts := newTestStruct()
+	var cw countingWriter
+	if err := NewEncoder(&cw).Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if cw.writes != 1 {
+		t.Fatalf("expected a single write and received %d", cw.writes)
+	}
+
+	if str := cw.buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+
+	cw = countingWriter{}
+	enc := NewEncoder(&cw)
+	enc.SetFlushThreshold(64)
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if cw.writes < 2 {
+		t.Fatalf("expected multiple writes past the flush threshold and received %d", cw.writes)
+	}
+
+	if str := cw.buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+}
+
+func TestMarshalRecovery(t *testing.T) {
+	ts := newTestStruct()
+	buf := bytes.NewBuffer(nil)
+	enc := NewEncoder(buf)
+
+	if err := enc.Encode(&failStruct{err: errTest}); err != errTest {
+		t.Fatalf("expected %v and received %v", errTest, err)
+	}
+
+	if err := enc.EncodeArray(failSlice{{err: errTest}}); err != errTest {
+		t.Fatalf("expected %v and received %v", errTest, err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic to be propagated")
+			}
+		}()
+
+		enc.Encode(&failStruct{panics: true})
+	}()
+
+	// Parents which ignore the error returned by a failing nested value must still fail the document
+	for _, is := range []*ignoreStruct{{}, {inArray: true}} {
+		if err := enc.Encode(is); err != errTest {
+			t.Fatalf("expected %v and received %v", errTest, err)
+		}
+
+		if !is.restored {
+			t.Fatal("expected the encoder state to be restored after the nested failure")
+		}
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected partial documents to be discarded, received %s", buf.String())
+	}
+
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+
+	buf2 := bytes.NewBuffer(nil)
+	enc.Reset(buf2)
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf2.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+
+	// With a flush threshold, output written before the failure remains but nothing is written after it
+	buf2.Reset()
+	enc.SetFlushThreshold(5)
+	if err := enc.Encode(partialStruct{}); err != errTest {
+		t.Fatalf("expected %v and received %v", errTest, err)
+	}
+
+	partial := `{"ok":{"value":"b"}`
+	if str := buf2.String(); str != partial {
+		t.Fatalf("invalid partial result\nExpected: %s\nReturned: %s\n", partial, str)
+	}
+
+	buf2.Reset()
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf2.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+}
+
+func TestMarshalStickyError(t *testing.T) {
+	ts := newTestStruct()
+	ts.Age = math.NaN()
+	buf := bytes.NewBuffer(nil)
+	enc := NewEncoder(buf)
+
+	if err := enc.Encode(&ts); err != ErrInvalidFloat {
+		t.Fatalf("expected %v and received %v", ErrInvalidFloat, err)
+	}
+
+	if err := enc.Err(); err != ErrInvalidFloat {
+		t.Fatalf("expected %v and received %v", ErrInvalidFloat, err)
+	}
+
+	if err := enc.EncodeNumber(math.Inf(1)); err != ErrInvalidFloat {
+		t.Fatalf("expected %v and received %v", ErrInvalidFloat, err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected invalid documents to be discarded, received %s", buf.String())
+	}
+
+	ts.Age = 32
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if enc.Err() != nil {
+		t.Fatalf("expected sticky error to be cleared, received %v", enc.Err())
+	}
+
+	if str := buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+
+	// Nested failures are recorded even when the parent ignores them
+	is := ignoreStruct{}
+	if err := enc.Encode(&is); err != errTest {
+		t.Fatalf("expected %v and received %v", errTest, err)
+	}
+
+	if err := enc.Err(); err != errTest {
+		t.Fatalf("expected %v and received %v", errTest, err)
+	}
+
+	if is.observed != errTest {
+		t.Fatalf("expected the nested failure to be visible during encoding, received %v", is.observed)
+	}
+
+	buf.Reset()
+	enc.SetStrictUTF8(true)
+	if err := enc.Encode(&stringStruct{Value: "bad \xff utf8"}); err != ErrInvalidUTF8 {
+		t.Fatalf("expected %v and received %v", ErrInvalidUTF8, err)
+	}
+
+	enc.SetMaxDepth(3)
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := enc.Encode(newDepthStruct(8)); err != ErrMaxDepth {
+		t.Fatalf("expected %v and received %v", ErrMaxDepth, err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected invalid documents to be discarded, received %s", buf.String())
+	}
+}
+
+func TestMarshalFloatPolicy(t *testing.T) {
+	fs := floatSlice{math.NaN(), math.Inf(1), math.Inf(-1), 1.5}
+	tests := []struct {
+		policy   FloatPolicy
+		expected string
+	}{
+		{FloatPolicyNull, `[null,null,null,1.5]`},
+		{FloatPolicyString, `["NaN","Infinity","-Infinity",1.5]`},
+		{FloatPolicyClamp, `[0,1.7976931348623157e+308,-1.7976931348623157e+308,1.5]`},
+	}
+
+	for _, tc := range tests {
+		buf := bytes.NewBuffer(nil)
+		enc := NewEncoder(buf)
+		enc.SetFloatPolicy(tc.policy)
+		if err := enc.EncodeArray(fs); err != nil {
+			t.Fatal(err)
+		}
+
+		if str := buf.String(); str != tc.expected {
+			t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", tc.expected, str)
+		}
+	}
+
+	if _, err := MarshalArray(fs); err != ErrInvalidFloat {
+		t.Fatalf("expected %v and received %v", ErrInvalidFloat, err)
+	}
+}
+
+func TestUnmarshalNaN(t *testing.T) {
+	var ns numberStruct
+	src := `{"value":NaN}`
+	if err := NewDecoder(bytes.NewReader([]byte(src))).Decode(&ns); !errors.Is(err, ErrInvalidChar) {
+		t.Fatalf("expected %v and received %v", ErrInvalidChar, err)
+	}
+
+	tests := []struct {
+		src string
+		fn  func(float64) bool
+		// Error returned when NaN and Infinity are not allowed
+		err error
+	}{
+		{`NaN`, math.IsNaN, ErrInvalidChar},
+		{`Infinity`, func(v float64) bool { return math.IsInf(v, 1) }, ErrInvalidChar},
+		{`-Infinity`, func(v float64) bool { return math.IsInf(v, -1) }, ErrInvalidNumber},
+	}
+
+	opts := UnmarshalOptions{AllowNaN: true}
+	for _, tc := range tests {
+		src := []byte(`{"value":` + tc.src + `,"after":true}`)
+		if err := Unmarshal(src, &ns); !errors.Is(err, tc.err) {
+			t.Fatalf("expected %v for %s and received %v", tc.err, tc.src, err)
+		}
+
+		dec := NewDecoder(bytes.NewReader(src))
+		dec.SetAllowNaN(true)
+		if err := dec.Decode(&ns); err != nil {
+			t.Fatal(err)
+		}
+
+		if !tc.fn(ns.Value) || !ns.After {
+			t.Fatalf("invalid value for %s, received %+v", tc.src, ns)
+		}
+
+		ns = numberStruct{}
+		if err := UnmarshalWith(src, &ns, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if !tc.fn(ns.Value) || !ns.After {
+			t.Fatalf("invalid value for %s, received %+v", tc.src, ns)
+		}
+	}
+
+	var fs floatSlice
+	if err := UnmarshalArrayWith([]byte(`[1,-Infinity,NaN]`), &fs, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fs) != 3 || !math.IsInf(fs[1], -1) || !math.IsNaN(fs[2]) {
+		t.Fatalf("invalid values, received %v", fs)
+	}
+
+	nope := []byte(`{"value":Nope}`)
+	dec := NewDecoder(bytes.NewReader(nope))
+	dec.SetAllowNaN(true)
+	if err := dec.Decode(&ns); !errors.Is(err, ErrInvalidChar) {
+		t.Fatalf("expected %v and received %v", ErrInvalidChar, err)
+	}
+
+	if err := UnmarshalWith(nope, &ns, opts); !errors.Is(err, ErrInvalidChar) {
+		t.Fatalf("expected %v and received %v", ErrInvalidChar, err)
+	}
+
+	// Options do not carry over to pooled scanners used by later calls
+	if err := Unmarshal([]byte(`{"value":NaN}`), &ns); !errors.Is(err, ErrInvalidChar) {
+		t.Fatalf("expected %v and received %v", ErrInvalidChar, err)
+	}
+}
+
+func TestMarshalFloatFormat(t *testing.T) {
+	values := []float64{0, 1, -1, 12.5, 0.1, 1e20, 1e21, 123456789e15, 1e-6, 1e-7, -2.5e-9, 6.02e23, math.MaxFloat64, math.SmallestNonzeroFloat64}
+	for _, v := range values {
+		bs, err := MarshalArray(floatSlice{v})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, _ := json.Marshal([]float64{v})
+		if string(bs) != string(expected) {
+			t.Fatalf("invalid result for %v\nExpected: %s\nReturned: %s\n", v, expected, bs)
+		}
+	}
+
+	fs := formatStruct{Price: 12.5, Ratio: 0.1, Large: 1e21}
+	bs, err := Marshal(&fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"price":12.50,"ratio":0.1,"large":1e+21,"values":[3.142,0.3]}`
+	if str := string(bs); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	ts := newTestStruct()
+	ns := nullStruct{Additionals: testSimpleStructSlice{}}
+	values := []Encodee{&ts, &ns, &intStruct{Values: intSlice{}}, mapStruct{}}
+	for _, v := range values {
+		compact, err := Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var expected bytes.Buffer
+		if err = json.Indent(&expected, compact, ">", "\t"); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := bytes.NewBuffer(nil)
+		enc := NewEncoder(buf)
+		enc.SetIndent(">", "\t")
+		if err = enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+
+		if str := buf.String(); str != expected.String() {
+			t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected.String(), str)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	enc := NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	if err := enc.EncodeArray(ts.Additionals[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[\n  {\n    \"dateCreated\": \"2017-01-01\",\n    \"lastLogin\": \"2017-01-01\"\n  }\n]"
+	if str := buf.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+
+	buf.Reset()
+	enc.SetIndent("", "")
+	if err := enc.Encode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != testStr {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", testStr, str)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	tcs := []struct {
+		src      string
+		err      error
+		offset   int64
+		line     int
+		column   int
+		b        byte
+		expected TokenClass
+		snippet  string
+	}{
+		{"{\n  \"value\": \"a\"\n  \"other\": 1\n}", ErrInvalidChar, 19, 3, 3, '"', TokenObjectSeparator, "\"value\": \"a\"\n  \""},
+		{"{\"value\" 1}", ErrInvalidChar, 9, 1, 10, '1', TokenColon, "{\"value\" 1"},
+		{"{\"value\":x}", ErrInvalidChar, 9, 1, 10, 'x', TokenValue, "{\"value\":x"},
+		{"{\"value\":01}", ErrInvalidNumber, 10, 1, 11, '1', TokenNumber, "{\"value\":01"},
+		{"{\"value\":\"\\q\"}", ErrInvalidEscape, 11, 1, 12, 'q', TokenEscape, "{\"value\":\"\\q"},
+		{"{\"value\":\"a\n\"}", ErrInvalidChar, 11, 1, 12, '\n', TokenString, "{\"value\":\"a\n"},
+		{"{\n\"value\":tru", ErrUnexpectedEnd, 13, 2, 12, 0, TokenLiteral, "{\n\"value\":tru"},
+	}
+
+	for _, tc := range tcs {
+		var ss stringStruct
+		errs := []error{
+			Unmarshal([]byte(tc.src), &ss),
+			NewDecoder(strings.NewReader(tc.src)).Decode(&ss),
+		}
+
+		for _, err := range errs {
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("invalid error for %q, expected %v and received %v", tc.src, tc.err, err)
+			}
+
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("expected a SyntaxError for %q, received %v", tc.src, err)
+			}
+
+			if se.Offset != tc.offset || se.Line != tc.line || se.Column != tc.column {
+				t.Fatalf("invalid position for %q, expected %d (%d:%d) and received %d (%d:%d)",
+					tc.src, tc.offset, tc.line, tc.column, se.Offset, se.Line, se.Column)
+			}
+
+			if se.Byte != tc.b || se.Expected != tc.expected || se.Snippet != tc.snippet {
+				t.Fatalf("invalid details for %q, expected %q/%v/%q and received %q/%v/%q",
+					tc.src, tc.b, tc.expected, tc.snippet, se.Byte, se.Expected, se.Snippet)
+			}
+		}
+	}
+}
+
+func TestFieldError(t *testing.T) {
+	tcs := []struct {
+		src      string
+		dec      Decodee
+		err      error
+		path     string
+		expected ValueType
+		actual   ValueType
+	}{
+		{
+			src:      `{"name":"a","additionals":[{"dateCreated":"b"},{"dateCreated":"c","lastLogin":5}]}`,
+			dec:      &testStruct{},
+			err:      ErrValueNotString,
+			path:     "additionals[1].lastLogin",
+			expected: TypeString,
+			actual:   TypeNumber,
+		},
+		{
+			src:      `{"additional":{"dateCreated":true}}`,
+			dec:      &testStruct{},
+			err:      ErrValueNotString,
+			path:     "additional.dateCreated",
+			expected: TypeString,
+			actual:   TypeBool,
+		},
+		{
+			src:      `{"int64":1,"int32":4294967296}`,
+			dec:      &intStruct{},
+			err:      ErrValueOverflow,
+			path:     "int32",
+			expected: TypeNumber,
+			actual:   TypeNumber,
+		},
+		{
+			src:      `{"a.b":{"c":1}}`,
+			dec:      &failStruct{err: errTest},
+			err:      errTest,
+			path:     `["a.b"]`,
+			expected: TypeObject,
+			actual:   TypeObject,
+		},
+		{
+			src:      `{"nested":{"value":"a"}}`,
+			dec:      &readFailStruct{err: errTest},
+			err:      errTest,
+			path:     "nested",
+			expected: TypeObject,
+			actual:   TypeObject,
+		},
+		{
+			src:      `{"nested":[1,2]}`,
+			dec:      &readFailStruct{err: errTest},
+			err:      errTest,
+			path:     "nested",
+			expected: TypeArray,
+			actual:   TypeArray,
+		},
+	}
+
+	for _, tc := range tcs {
+		errs := []error{
+			Unmarshal([]byte(tc.src), tc.dec),
+			NewDecoder(strings.NewReader(tc.src)).Decode(tc.dec),
+		}
+
+		for _, err := range errs {
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("invalid error for %s, expected %v and received %v", tc.src, tc.err, err)
+			}
+
+			var fe *FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("expected a FieldError for %s, received %v", tc.src, err)
+			}
+
+			if fe.Path != tc.path || fe.Expected != tc.expected || fe.Actual != tc.actual {
+				t.Fatalf("invalid details for %s, expected %s (%v/%v) and received %s (%v/%v)",
+					tc.src, tc.path, tc.expected, tc.actual, fe.Path, fe.Expected, fe.Actual)
+			}
+		}
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	first := `{"value":"a"}`
+	src := first + "\n [{\"dateCreated\":\"framed\"}]trailer"
+	// MultiReader does not implement ReadByter, so the Decoder buffers the input
+	dec := NewDecoder(io.MultiReader(strings.NewReader(src)))
+	if !dec.More() {
+		t.Fatal("expected more values before decoding")
+	}
+
+	var ss stringStruct
+	if err := dec.Decode(&ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if ss.Value != "a" {
+		t.Fatalf("invalid value, expected %q and received %q", "a", ss.Value)
+	}
+
+	if offset := dec.InputOffset(); offset != int64(len(first)) {
+		t.Fatalf("invalid offset, expected %d and received %d", len(first), offset)
+	}
+
+	if !dec.More() {
+		t.Fatal("expected more values after the first document")
+	}
+
+	var ts testSimpleStructSlice
+	if err := dec.Decode(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ts) != 1 || ts[0].DateCreated != "framed" {
+		t.Fatalf("invalid array, received %v", ts)
+	}
+
+	rest, err := io.ReadAll(dec.Buffered())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(rest) != "trailer" {
+		t.Fatalf("invalid buffered data, expected %q and received %q", "trailer", rest)
+	}
+
+	if offset := dec.InputOffset(); offset != int64(len(src)-len(rest)) {
+		t.Fatalf("invalid offset, expected %d and received %d", len(src)-len(rest), offset)
+	}
+
+	if dec = NewDecoder(strings.NewReader(" ] ")); dec.More() {
+		t.Fatal("expected no more values before a closing bracket")
+	}
+
+	if dec = NewDecoder(strings.NewReader(" \n")); dec.More() {
+		t.Fatal("expected no more values at the end of the input")
+	}
+}
+
+func TestDecodeMultiple(t *testing.T) {
+	src := `{"value":"a"}{"value":"b"}` + "\n\n" + ` {"value":"c"} ` + "\n"
+	dec := NewDecoder(strings.NewReader(src))
+
+	var values []string
+	for {
+		var ss stringStruct
+		err := dec.Decode(&ss)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		values = append(values, ss.Value)
+	}
+
+	if str := strings.Join(values, ","); str != "a,b,c" {
+		t.Fatalf("invalid values, expected %q and received %q", "a,b,c", str)
+	}
+
+	dec = NewDecoder(strings.NewReader(`{"value":"a"} {"value":`))
+	var ss stringStruct
+	if err := dec.Decode(&ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dec.Decode(&ss); !errors.Is(err, ErrUnexpectedEnd) {
+		t.Fatalf("invalid error, expected %v and received %v", ErrUnexpectedEnd, err)
+	}
+}
+
+func TestDecodeLines(t *testing.T) {
+	src := "{\"value\":\"a\"}\n" +
+		"{\"value\":\n" +
+		"\n" +
+		"{\"value\":\"b\"}\r\n" +
+		"{\"value\":\"c\"} trailing\n" +
+		"42\n" +
+		"{\"value\":5}\n" +
+		"{\"value\":\"d\"}"
+
+	var (
+		values  []string
+		badLine []int
+	)
+
+	err := NewDecoder(strings.NewReader(src)).DecodeLines(func(val *Value, err error) error {
+		var le *LineError
+		if errors.As(err, &le) {
+			badLine = append(badLine, le.Line)
+			return nil
+		}
+
+		switch val.Type() {
+		case TypeObject:
+			var ss stringStruct
+			if err := val.Object(&ss); err != nil {
+				// Type mismatches are left to the LineFunc, here we skip the line
+				return nil
+			}
+
+			values = append(values, ss.Value)
+		case TypeNumber:
+			n, err := val.Int()
+			if err != nil {
+				return err
+			}
+
+			values = append(values, strconv.Itoa(n))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if str := strings.Join(values, ","); str != "a,b,42,d" {
+		t.Fatalf("invalid values, expected %q and received %q", "a,b,42,d", str)
+	}
+
+	if len(badLine) != 2 || badLine[0] != 2 || badLine[1] != 5 {
+		t.Fatalf("invalid bad lines, expected [2 5] and received %v", badLine)
+	}
+
+	var ns []float64
+	dec := NewDecoder(strings.NewReader("{\"value\":NaN}\n[-Infinity]\n"))
+	dec.SetAllowNaN(true)
+	err = dec.DecodeLines(func(val *Value, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch val.Type() {
+		case TypeObject:
+			var n numberStruct
+			err = val.Object(&n)
+			ns = append(ns, n.Value)
+		case TypeArray:
+			var fs floatSlice
+			err = val.Array(&fs)
+			ns = append(ns, fs...)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ns) != 2 || !math.IsNaN(ns[0]) || !math.IsInf(ns[1], -1) {
+		t.Fatalf("invalid values, expected [NaN -Inf] and received %v", ns)
+	}
+
+	err = NewDecoder(strings.NewReader("[1]\n[2]\n")).DecodeLines(func(val *Value, err error) error {
+		return errTest
+	})
+
+	if err != errTest {
+		t.Fatalf("invalid error, expected %v and received %v", errTest, err)
+	}
+}
+
+func TestLineEncoder(t *testing.T) {
+	var out bytes.Buffer
+	bw := bufio.NewWriter(&out)
+	enc := NewLineEncoder(bw)
+	enc.SetFlushRecords(2)
+
+	if err := enc.Encode(&stringStruct{Value: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Encode(&failStruct{err: errTest}); err != errTest {
+		t.Fatalf("invalid error, expected %v and received %v", errTest, err)
+	}
+
+	if out.Len() != 0 {
+		t.Fatalf("expected no output before the flush, received %q", out.String())
+	}
+
+	if err := enc.EncodeArray(stringArray{"b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"value":"a"}` + "\n" + `["b"]` + "\n"
+	if str := out.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+
+	if err := enc.Encode(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected += "null\n"
+	if str := out.String(); str != expected {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected, str)
+	}
+
+	var fw flushWriter
+	enc = NewLineEncoder(&fw)
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(&stringStruct{Value: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if fw.writes != 3 || fw.flushes != 3 {
+		t.Fatalf("expected every record to be flushed, received %d writes and %d flushes", fw.writes, fw.flushes)
+	}
+
+	// Time passes between records, the timer must flush without another record being encoded
+	fw = flushWriter{flushed: make(chan struct{}, 1)}
+	enc = NewLineEncoder(&fw)
+	enc.SetFlushRecords(100)
+	enc.SetFlushInterval(time.Millisecond)
+	if err := enc.Encode(&stringStruct{Value: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fw.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the interval to flush the idle writer")
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fw.writes != 1 || fw.flushes != 2 {
+		t.Fatalf("expected one write and two flushes, received %d writes and %d flushes", fw.writes, fw.flushes)
+	}
+
+	// Records are written as they are encoded, only the flushes are batched
+	fw = flushWriter{}
+	enc = NewLineEncoder(&fw)
+	enc.SetFlushRecords(2)
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(&stringStruct{Value: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if fw.writes != 3 || fw.flushes != 1 {
+		t.Fatalf("expected one write per record and one flush, received %d writes and %d flushes", fw.writes, fw.flushes)
+	}
+
+	// Encoder options apply to each record
+	out.Reset()
+	enc = NewLineEncoder(&out)
+	if err := enc.EncodeArray(floatSlice{math.NaN()}); err != ErrInvalidFloat {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidFloat, err)
+	}
+
+	enc.SetFloatPolicy(FloatPolicyNull)
+	if err := enc.EncodeArray(floatSlice{math.NaN()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := out.String(); str != "[null]\n" {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", "[null]\n", str)
+	}
+}
+
+func TestValid(t *testing.T) {
+	valid := []string{
+		testStr,
+		testExpanded,
+		`"hello"`,
+		" -1.5e3\r\n",
+		`[]`,
+		`{"a":[{},[],null,true,false,"\u00e9"]}`,
+	}
+
+	for _, str := range valid {
+		if !Valid([]byte(str)) {
+			t.Fatalf("expected %q to be valid", str)
+		}
+	}
+
+	invalid := []string{
+		``,
+		`{`,
+		`{"a":}`,
+		`{"a":1,}`,
+		`[1,]`,
+		`[1 2]`,
+		`{"a":1}}`,
+		"\"tab\tinside\"",
+		`"\x"`,
+		`01`,
+		`tru`,
+		`{"a":[{"b":nul}]}`,
+	}
+
+	for _, str := range invalid {
+		if Valid([]byte(str)) {
+			t.Fatalf("expected %q to be invalid", str)
+		}
+
+		if json.Valid([]byte(str)) {
+			t.Fatalf("expected %q to be invalid for the standard library", str)
+		}
+	}
+}
+
+func TestValidNesting(t *testing.T) {
+	nested := func(depth int, open, close string) []byte {
+		return []byte(strings.Repeat(open, depth) + strings.Repeat(close, depth))
+	}
+
+	if !Valid(nested(maxNesting, "[", "]")) {
+		t.Fatalf("expected %d nested arrays to be valid", maxNesting)
+	}
+
+	if !Valid([]byte(strings.Repeat(`{"a":`, maxNesting-1) + "[]" + strings.Repeat("}", maxNesting-1))) {
+		t.Fatalf("expected %d nested objects to be valid", maxNesting)
+	}
+
+	// Deeply nested input must fail without overflowing the stack
+	src := nested(5000000, "[", "]")
+	if Valid(src) {
+		t.Fatal("expected deeply nested arrays to be invalid")
+	}
+
+	var buf bytes.Buffer
+	err := Compact(&buf, src)
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Fatalf("invalid error, expected %v and received %v", ErrMaxDepth, err)
+	}
+
+	var se *SyntaxError
+	if !errors.As(err, &se) || se.Offset != maxNesting {
+		t.Fatalf("expected a SyntaxError at offset %d, received %v", maxNesting, err)
+	}
+
+	if err = Indent(&buf, []byte(strings.Repeat(`{"a":`, maxNesting+1)), "", "\t"); !errors.Is(err, ErrMaxDepth) {
+		t.Fatalf("invalid error, expected %v and received %v", ErrMaxDepth, err)
+	}
+
+	// The depth is restored for pooled scanners used by later calls
+	if !Valid(nested(maxNesting, "[", "]")) {
+		t.Fatalf("expected %d nested arrays to be valid", maxNesting)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Compact(&buf, []byte(testExpanded)); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected bytes.Buffer
+	if err := json.Compact(&expected, []byte(testExpanded)); err != nil {
+		t.Fatal(err)
+	}
+
+	if str := buf.String(); str != expected.String() {
+		t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected.String(), str)
+	}
+
+	buf.Reset()
+	if err := Compact(&buf, []byte(`{"a": "b \" c" ,`)); !errors.Is(err, ErrUnexpectedEnd) {
+		t.Fatalf("invalid error, expected %v and received %v", ErrUnexpectedEnd, err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected buffer to be unchanged, received %q", buf.String())
+	}
+}
+
+func TestIndent(t *testing.T) {
+	sources := []string{
+		testStr,
+		testExpanded,
+		`{"a":{},"b":[ ],"c":"x\"{[,:"}`,
+		`  42  `,
+	}
+
+	for _, src := range sources {
+		var buf bytes.Buffer
+		if err := Indent(&buf, []byte(src), ">", "\t"); err != nil {
+			t.Fatal(err)
+		}
+
+		var compact, expected bytes.Buffer
+		if err := json.Compact(&compact, []byte(src)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := json.Indent(&expected, compact.Bytes(), ">", "\t"); err != nil {
+			t.Fatal(err)
+		}
+
+		if str := buf.String(); str != expected.String() {
+			t.Fatalf("invalid result\nExpected: %s\nReturned: %s\n", expected.String(), str)
+		}
+	}
+}
+
 func BenchmarkJsoonMarshal(b *testing.B) {
 	ts := newTestStruct()
 	buf := bytes.NewBuffer(make([]byte, 0, 512))
@@ -133,6 +1699,17 @@ func BenchmarkJsoonUnmarshal(b *testing.B) {
 	b.ReportAllocs()
 }
 
+func BenchmarkJsoonUnmarshalBytes(b *testing.B) {
+	var ts testStruct
+	data := []byte(testStr)
+
+	for i := 0; i < b.N; i++ {
+		Unmarshal(data, &ts)
+	}
+
+	b.ReportAllocs()
+}
+
 func BenchmarkJsoonUnmarshalPara(b *testing.B) {
 	b.RunParallel(func(p *testing.PB) {
 		var ts testStruct
@@ -399,3 +1976,474 @@ func (t *testSimpleStructSlice) UnmarshalJsoon(val *Value) (err error) {
 	*t = append(*t, &ts)
 	return
 }
+
+type numberStruct struct {
+	Value float64
+	After bool
+}
+
+func (n *numberStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	switch key {
+	case "value":
+		n.Value, err = val.Number()
+	case "after":
+		n.After, err = val.Bool()
+	}
+
+	return
+}
+
+type intStruct struct {
+	Int64    int64
+	Uint64   uint64
+	Int32    int32
+	Negative int
+	Values   intSlice
+}
+
+func (i *intStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Int64("int64", i.Int64)
+	enc.Uint64("uint64", i.Uint64)
+	enc.Int64("int32", int64(i.Int32))
+	enc.Int("negative", i.Negative)
+	enc.Array("values", i.Values)
+	return
+}
+
+func (i *intStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	switch key {
+	case "int64":
+		i.Int64, err = val.Int64()
+	case "uint64":
+		i.Uint64, err = val.Uint64()
+	case "int32":
+		i.Int32, err = val.Int32()
+	case "negative":
+		i.Negative, err = val.Int()
+	}
+
+	return
+}
+
+type intSlice []int64
+
+func (i intSlice) MarshalJsoon(a *ArrayEncoder) (err error) {
+	for _, v := range i {
+		a.Int64(v)
+	}
+
+	return
+}
+
+type stringStruct struct {
+	Value string
+}
+
+func (s *stringStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.String("value", s.Value)
+	return
+}
+
+func (s *stringStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	switch key {
+	case "value":
+		s.Value, err = val.String()
+	}
+
+	return
+}
+
+type skipStruct struct {
+	Value string
+}
+
+func (s *skipStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	switch key {
+	case "skipped":
+		err = val.Skip()
+	case "value":
+		s.Value, err = val.String()
+	}
+
+	return
+}
+
+// mapStruct is an ordered set of key/value pairs with dynamic keys
+type mapStruct [][2]string
+
+func (m mapStruct) MarshalJsoon(enc *Encoder) (err error) {
+	for _, kv := range m {
+		enc.String(kv[0], kv[1])
+	}
+
+	return
+}
+
+type stringSlice []string
+
+func (s *stringSlice) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Array("values", stringArray(*s))
+	enc.Array("unsafe", unsafeStringArray(*s))
+	return
+}
+
+type stringArray []string
+
+func (s stringArray) MarshalJsoon(a *ArrayEncoder) (err error) {
+	for _, v := range s {
+		a.String(v)
+	}
+
+	return
+}
+
+type unsafeStringArray []string
+
+func (s unsafeStringArray) MarshalJsoon(a *ArrayEncoder) (err error) {
+	for _, v := range s {
+		a.UnsafeString(v)
+	}
+
+	return
+}
+
+type nullStruct struct {
+	Additional  *testSimpleStruct
+	Additionals testSimpleStructSlice
+	Values      *stringSlice
+}
+
+func (n *nullStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Null("null")
+	enc.Object("additional", n.Additional)
+	enc.Object("nilInterface", nil)
+	enc.Array("additionals", n.Additionals)
+	enc.Object("values", n.Values)
+	return
+}
+
+// ptrWrap is a pointer-shaped value type, its only field is a pointer
+type ptrWrap struct {
+	p *testSimpleStruct
+}
+
+func (p ptrWrap) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Bool("set", p.p != nil)
+	return
+}
+
+// nilMap is a map type which implements Encodee
+type nilMap map[string]string
+
+func (n nilMap) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Int("len", len(n))
+	return
+}
+
+type typeStruct struct {
+	types map[string]ValueType
+	null  bool
+}
+
+func (t *typeStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	if t.types == nil {
+		t.types = make(map[string]ValueType)
+	}
+
+	// Keys reference the decoder buffer, so they must be copied before being retained
+	t.types[strings.Clone(key)] = val.Type()
+	if key == "null" {
+		t.null = val.IsNull()
+	}
+
+	return
+}
+
+type nullableStruct struct {
+	String string
+	Bytes  []byte
+	Number float64
+	Int    int
+	Uint   uint64
+	Bool   bool
+	Object *testSimpleStruct
+	Array  testSimpleStructSlice
+}
+
+func (n *nullableStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	switch key {
+	case "string":
+		n.String, err = val.String()
+	case "bytes":
+		n.Bytes, err = val.Bytes()
+	case "number":
+		n.Number, err = val.Number()
+	case "int":
+		n.Int, err = val.Int()
+	case "uint":
+		n.Uint, err = val.Uint64()
+	case "bool":
+		n.Bool, err = val.Bool()
+	case "object":
+		err = val.Object(n.Object)
+	case "array":
+		err = val.Array(&n.Array)
+	}
+
+	return
+}
+
+// bytesStruct records whether or not decoded bytes reference the source data
+type bytesStruct struct {
+	plainShared   bool
+	escapedShared bool
+	numberShared  bool
+	escaped       string
+}
+
+func (b *bytesStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	var bs []byte
+	if bs, err = val.Bytes(); err != nil && err != ErrValueNotBytes {
+		return
+	}
+
+	if val.Type() == TypeNumber {
+		bs = val.b
+	}
+
+	switch key {
+	case "plain":
+		b.plainShared = isShared(bs, val)
+	case "escaped":
+		b.escapedShared = isShared(bs, val)
+		if len(b.escaped) == 0 {
+			b.escaped = string(bs)
+		}
+	case "number":
+		b.numberShared = isShared(bs, val)
+	}
+
+	return nil
+}
+
+// isShared will return whether or not the provided bytes reference the scanner's source data
+func isShared(bs []byte, val *Value) bool {
+	s, ok := val.src.(*scanner)
+	if !ok || len(bs) == 0 {
+		return false
+	}
+
+	start := uintptr(unsafe.Pointer(&s.data[0]))
+	end := start + uintptr(len(s.data))
+	ptr := uintptr(unsafe.Pointer(&bs[0]))
+	return ptr >= start && ptr < end
+}
+
+// countingWriter counts the number of writes it receives
+type countingWriter struct {
+	buf    bytes.Buffer
+	writes int
+}
+
+func (c *countingWriter) Write(bs []byte) (n int, err error) {
+	c.writes++
+	return c.buf.Write(bs)
+}
+
+var errTest = errors.New("test error")
+
+// failStruct fails partway through being marshaled
+type failStruct struct {
+	err    error
+	panics bool
+}
+
+func (f *failStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.String("before", "value")
+	enc.Array("nested", failSlice{{}, {err: f.err}})
+	if f.panics {
+		panic("test panic")
+	}
+
+	return f.err
+}
+
+func (f *failStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	return f.err
+}
+
+// readFailStruct returns an error after successfully reading a nested value
+type readFailStruct struct {
+	err error
+}
+
+func (r *readFailStruct) UnmarshalJsoon(key string, val *Value) (err error) {
+	switch val.Type() {
+	case TypeObject:
+		var ss stringStruct
+		err = val.Object(&ss)
+	case TypeArray:
+		var fs floatSlice
+		err = val.Array(&fs)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return r.err
+}
+
+// flushWriter counts writes and flushes, similar to an http.ResponseWriter implementing http.Flusher
+type flushWriter struct {
+	bytes.Buffer
+	writes  int
+	flushes int
+	// Signalled on each flush when set
+	flushed chan struct{}
+}
+
+func (f *flushWriter) Write(bs []byte) (n int, err error) {
+	f.writes++
+	return f.Buffer.Write(bs)
+}
+
+func (f *flushWriter) Flush() {
+	f.flushes++
+	if f.flushed != nil {
+		select {
+		case f.flushed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ignoreStruct ignores the error returned by a failing nested value
+type ignoreStruct struct {
+	inArray bool
+	// Whether or not the depth and child count were restored after the nested failure
+	restored bool
+	// Sticky error observed after the nested failure
+	observed error
+}
+
+func (i *ignoreStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.String("before", "value")
+	depth, child := enc.depth, enc.child
+	if i.inArray {
+		enc.Array("nested", ignoreSlice{})
+	} else {
+		enc.Object("nested", &failStruct{err: errTest})
+	}
+
+	// The failed member is still counted, so following members are separated correctly
+	i.restored = enc.depth == depth && enc.child == child+1
+	i.observed = enc.Err()
+	enc.String("after", "value")
+	return
+}
+
+// ignoreSlice ignores the error returned by a failing object within an array
+type ignoreSlice struct{}
+
+func (ignoreSlice) MarshalJsoon(a *ArrayEncoder) (err error) {
+	a.Object(&failStruct{err: errTest})
+	a.String("after")
+	return
+}
+
+type failSlice []*failStruct
+
+func (f failSlice) MarshalJsoon(a *ArrayEncoder) (err error) {
+	for _, v := range f {
+		if v.err != nil {
+			return v.err
+		}
+
+		a.String("value")
+	}
+
+	return
+}
+
+// nestedEncodeStruct calls a top-level Encode method from within MarshalJsoon
+type nestedEncodeStruct struct {
+	fn func(enc *Encoder) error
+	// Error returned by fn
+	err error
+}
+
+func (n *nestedEncodeStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Int("a", 1)
+	// The error is deliberately ignored, the document must still fail
+	n.err = n.fn(enc)
+	return
+}
+
+// partialStruct writes a complete nested object followed by a nested object which fails
+type partialStruct struct{}
+
+func (partialStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Object("ok", &stringStruct{Value: "b"})
+	return enc.Object("fail", &failStruct{err: errTest})
+}
+
+// depthStruct is a chain of nested objects
+type depthStruct struct {
+	child *depthStruct
+}
+
+func newDepthStruct(depth int) (d *depthStruct) {
+	for i := 0; i < depth; i++ {
+		d = &depthStruct{child: d}
+	}
+
+	return
+}
+
+func (d *depthStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Object("child", d.child)
+	return
+}
+
+type floatSlice []float64
+
+func (f floatSlice) MarshalJsoon(a *ArrayEncoder) (err error) {
+	for _, v := range f {
+		a.Number(v)
+	}
+
+	return
+}
+
+func (f *floatSlice) UnmarshalJsoon(val *Value) (err error) {
+	var v float64
+	if v, err = val.Number(); err != nil {
+		return
+	}
+
+	*f = append(*f, v)
+	return
+}
+
+type formatStruct struct {
+	Price float64
+	Ratio float32
+	Large float32
+}
+
+func (f *formatStruct) MarshalJsoon(enc *Encoder) (err error) {
+	enc.Fixed("price", f.Price, 2)
+	enc.Float32("ratio", f.Ratio)
+	enc.Float32("large", f.Large)
+	enc.Array("values", formatSlice{})
+	return
+}
+
+type formatSlice struct{}
+
+func (f formatSlice) MarshalJsoon(a *ArrayEncoder) (err error) {
+	a.Fixed(math.Pi, 3)
+	a.Float32(0.3)
+	return
+}
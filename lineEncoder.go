@@ -0,0 +1,222 @@
+package jsoon
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// NewLineEncoder will return a new LineEncoder
+func NewLineEncoder(w io.Writer) *LineEncoder {
+	var l LineEncoder
+	l.e.w = w
+	l.e.lineDelimited = true
+	return &l
+}
+
+// LineEncoder handles encoding newline-delimited JSON (also known as JSON Lines), writing each record on its own line
+// Each record is encoded within a pooled buffer and written to the writer with a single Write call, the writer is then
+// flushed every N records or T duration. By default, the writer is flushed as soon as each record is written
+// Note: Indentation is not supported, as each record must remain on a single line. When a flush interval is set, the
+// writer is flushed from another goroutine, so the LineEncoder synchronizes access to the writer and is safe for
+// concurrent use. Close must be called once encoding is complete to flush any remaining records and stop the timer
+type LineEncoder struct {
+	mux sync.Mutex
+
+	// Encoder used for each record, it writes completed records (including the trailing newline) to the writer
+	e Encoder
+
+	// Number of records written since the last flush
+	pending int
+	// Number of records which will cause a flush, zero flushes every record unless an interval is set
+	flushRecords int
+	// Maximum duration a written record waits before the writer is flushed
+	flushInterval time.Duration
+
+	// Timer used to flush the writer once the interval has passed
+	timer *time.Timer
+	// Whether or not the timer is currently scheduled
+	scheduled bool
+	// Error encountered by a timed flush, returned by the next call
+	err error
+}
+
+// SetFlushRecords will set the number of written records which will cause the writer to be flushed. When zero (the
+// default) and no interval is set, every record is flushed
+func (l *LineEncoder) SetFlushRecords(n int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.flushRecords = n
+}
+
+// SetFlushInterval will set the maximum duration a written record waits before the writer is flushed. The writer is
+// flushed by a timer, so records reach the writer's destination even when no further records are encoded
+func (l *LineEncoder) SetFlushInterval(d time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.flushInterval = d
+}
+
+// SetFloatPolicy will set how NaN and infinite floats are encoded, see Encoder.SetFloatPolicy
+func (l *LineEncoder) SetFloatPolicy(policy FloatPolicy) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.e.SetFloatPolicy(policy)
+}
+
+// SetMaxDepth will set the maximum depth of nested objects and arrays, see Encoder.SetMaxDepth
+func (l *LineEncoder) SetMaxDepth(n int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.e.SetMaxDepth(n)
+}
+
+// SetStrictUTF8 will set whether or not strings containing invalid UTF-8 cause the encode to fail, see
+// Encoder.SetStrictUTF8
+func (l *LineEncoder) SetStrictUTF8(strict bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.e.SetStrictUTF8(strict)
+}
+
+// SetEscapeLineTerminators will set whether or not U+2028 and U+2029 are escaped within strings, see
+// Encoder.SetEscapeLineTerminators
+func (l *LineEncoder) SetEscapeLineTerminators(escape bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.e.SetEscapeLineTerminators(escape)
+}
+
+// SetUnsafeKeys will set whether or not keys are written without escaping, see Encoder.SetUnsafeKeys
+func (l *LineEncoder) SetUnsafeKeys(unsafe bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.e.SetUnsafeKeys(unsafe)
+}
+
+// Encode will encode an Encodee as a single record
+// Note: If an error is returned, the record is discarded and nothing is written. An error from a timed flush is
+// returned by the next call, in which case the record is not encoded
+func (l *LineEncoder) Encode(value Encodee) (err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if err = l.takeErr(); err != nil {
+		return
+	}
+
+	if err = l.e.Encode(value); err != nil {
+		return
+	}
+
+	return l.endRecord()
+}
+
+// EncodeArray will encode an ArrayEncodee as a single record
+// Note: If an error is returned, the record is discarded and nothing is written. An error from a timed flush is
+// returned by the next call, in which case the record is not encoded
+func (l *LineEncoder) EncodeArray(value ArrayEncodee) (err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if err = l.takeErr(); err != nil {
+		return
+	}
+
+	if err = l.e.EncodeArray(value); err != nil {
+		return
+	}
+
+	return l.endRecord()
+}
+
+// Flush will flush the writer. Writers such as http.ResponseWriter (through http.Flusher) and bufio.Writer are flushed
+func (l *LineEncoder) Flush() (err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if err = l.takeErr(); err != nil {
+		return
+	}
+
+	return l.flush()
+}
+
+// Close will flush the writer and stop the flush timer once encoding is complete. The LineEncoder may continue to be
+// used after Close is called
+func (l *LineEncoder) Close() (err error) {
+	return l.Flush()
+}
+
+// endRecord will count the written record, flushing (or scheduling a flush) when needed
+func (l *LineEncoder) endRecord() (err error) {
+	l.pending++
+	switch {
+	case l.flushRecords == 0 && l.flushInterval == 0:
+		// No batching, every record is flushed
+		return l.flush()
+	case l.flushRecords > 0 && l.pending >= l.flushRecords:
+		return l.flush()
+	case l.flushInterval > 0 && !l.scheduled:
+		l.schedule()
+	}
+
+	return
+}
+
+// schedule will start the timer which flushes the writer once the interval has passed
+func (l *LineEncoder) schedule() {
+	l.scheduled = true
+	if l.timer == nil {
+		l.timer = time.AfterFunc(l.flushInterval, l.timedFlush)
+		return
+	}
+
+	l.timer.Reset(l.flushInterval)
+}
+
+// timedFlush is called by the timer (on its own goroutine) to flush records which have been waiting for the interval
+func (l *LineEncoder) timedFlush() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if !l.scheduled {
+		// The records were flushed before the timer fired
+		return
+	}
+
+	if err := l.flush(); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// flush will flush the writer and stop any scheduled flush
+func (l *LineEncoder) flush() (err error) {
+	l.pending = 0
+	if l.scheduled {
+		l.timer.Stop()
+		l.scheduled = false
+	}
+
+	switch f := l.e.w.(type) {
+	case errorFlusher:
+		err = f.Flush()
+	case flusher:
+		f.Flush()
+	}
+
+	return
+}
+
+// takeErr will return and clear the error encountered by a timed flush
+func (l *LineEncoder) takeErr() (err error) {
+	err = l.err
+	l.err = nil
+	return
+}
+
+// flusher is implemented by writers which can be flushed, such as http.ResponseWriter
+type flusher interface {
+	Flush()
+}
+
+// errorFlusher is implemented by writers which can be flushed and may fail, such as bufio.Writer
+type errorFlusher interface {
+	Flush() error
+}
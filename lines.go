@@ -0,0 +1,91 @@
+package jsoon
+
+import (
+	"io"
+	"strconv"
+)
+
+// LineFunc is called by DecodeLines for each line of input which contains a value
+// When a line cannot be decoded, LineFunc is called with a nil Value and a *LineError describing the failure.
+// Returning nil skips the line and continues decoding, while returning an error stops DecodeLines with that error
+// Note: Please do not hold onto the Value (or any bytes it returns) after LineFunc returns
+type LineFunc func(val *Value, err error) error
+
+// LineError is provided to a LineFunc when a line cannot be decoded
+type LineError struct {
+	// Line is the one-based line number within the input
+	Line int
+	// Err is the underlying error, positions within a SyntaxError are relative to the start of the line
+	Err error
+}
+
+// Error will return the error message, including the line number
+func (e *LineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap will return the underlying error
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeLines will decode newline-delimited JSON (also known as JSON Lines), calling fn with the value of each line
+// until the end of the input. Blank lines are ignored, and each line is validated before it is provided so a
+// malformed line never affects the lines which follow it
+func (d *Decoder) DecodeLines(fn LineFunc) (err error) {
+	var (
+		b byte
+		// Error encountered while reading the current line
+		readErr error
+		// Current line number
+		line int
+	)
+
+	lb := p.Acquire()
+	s := p.AcquireScanner(nil)
+	for readErr == nil {
+		lb.Reset()
+		for b, readErr = d.r.ReadByte(); readErr == nil && b != charNewline; b, readErr = d.r.ReadByte() {
+			lb.WriteByte(b)
+		}
+
+		if readErr != nil && readErr != io.EOF {
+			err = readErr
+			break
+		}
+
+		line++
+		if err = d.decodeLine(s, lb.Bytes(), line, fn); err != nil {
+			break
+		}
+	}
+
+	p.ReleaseScanner(s)
+	p.Release(lb)
+	return
+}
+
+// decodeLine will validate a single line and provide its value to fn
+// Note: The scanner is reset for each line, so the Decoder's options are applied after every reset
+func (d *Decoder) decodeLine(s *scanner, data []byte, line int, fn LineFunc) (err error) {
+	if s.reset(data); s.skipWhitespace() {
+		// Blank line
+		return
+	}
+
+	s.allowNaN = d.allowNaN
+
+	if err = s.Validate(); err != nil {
+		return fn(nil, &LineError{Line: line, Err: err})
+	}
+
+	// The line is valid, so we rewind and read the value for the LineFunc
+	s.reset(data)
+	s.allowNaN = d.allowNaN
+	s.skipWhitespace()
+	if s.v.vt, s.v.b, err = s.readValue(); err != nil {
+		return fn(nil, &LineError{Line: line, Err: err})
+	}
+
+	return fn(&s.v, nil)
+}
@@ -16,6 +16,12 @@ func newPool() *pool {
 		},
 	}
 
+	p.sp = sync.Pool{
+		New: func() interface{} {
+			return newScanner()
+		},
+	}
+
 	return &p
 }
 
@@ -23,6 +29,8 @@ type pool struct {
 	p sync.Pool
 	// array encoder pool
 	aep sync.Pool
+	// scanner pool
+	sp sync.Pool
 }
 
 // Acquire will acquire a buffer from the pool
@@ -57,3 +65,20 @@ func (p *pool) ReleaseAE(ae *ArrayEncoder) {
 	ae.e = nil
 	p.aep.Put(ae)
 }
+
+// AcquireScanner will acquire a scanner from the pool
+func (p *pool) AcquireScanner(data []byte) (s *scanner) {
+	var ok bool
+	if s, ok = p.sp.Get().(*scanner); !ok {
+		panic("invalid pool type")
+	}
+
+	s.reset(data)
+	return
+}
+
+// ReleaseScanner will release a scanner to the pool
+func (p *pool) ReleaseScanner(s *scanner) {
+	s.reset(nil)
+	p.sp.Put(s)
+}
@@ -0,0 +1,91 @@
+package jsoon
+
+// positionReader wraps a ReadByter, tracking the position within the input and the most recently read bytes
+type positionReader struct {
+	r ReadByter
+
+	// Number of bytes read
+	off int64
+	// Zero-based line of the next byte
+	line int
+	// Offset at which the current line starts
+	lineStart int64
+	// Offset at which the previous line started, used when unreading a newline
+	prevLineStart int64
+
+	// Most recently read bytes, indexed by offset modulo the snippet size
+	recent [snippetSize]byte
+}
+
+// ReadByte will read a single byte
+func (r *positionReader) ReadByte() (b byte, err error) {
+	if b, err = r.r.ReadByte(); err != nil {
+		return
+	}
+
+	r.recent[r.off%snippetSize] = b
+	r.off++
+	if b == charNewline {
+		r.line++
+		r.prevLineStart = r.lineStart
+		r.lineStart = r.off
+	}
+
+	return
+}
+
+// UnreadByte will unread the previously read byte
+func (r *positionReader) UnreadByte() (err error) {
+	if err = r.r.UnreadByte(); err != nil {
+		return
+	}
+
+	r.off--
+	if r.recent[r.off%snippetSize] == charNewline {
+		r.line--
+		r.lineStart = r.prevLineStart
+	}
+
+	return
+}
+
+// syntaxError will attach the current position to a syntax sentinel error
+// Note: The offending byte is the most recently read byte, unless the input ended early
+func (r *positionReader) syntaxError(err error, expected TokenClass) error {
+	if !isSyntaxSentinel(err) {
+		// Errors which are already positioned, or which did not originate from the input, are returned as-is
+		return err
+	}
+
+	e := SyntaxError{
+		Err:      err,
+		Offset:   r.off,
+		Line:     r.line + 1,
+		Column:   int(r.off-r.lineStart) + 1,
+		Expected: expectationFor(err, expected),
+	}
+
+	end := r.off
+	if err != ErrUnexpectedEnd && r.off > 0 {
+		e.Offset--
+		e.Byte = r.recent[e.Offset%snippetSize]
+		if e.Column--; e.Byte == charNewline {
+			// The offending newline belongs to the line it ends
+			e.Line--
+			e.Column = int(e.Offset-r.prevLineStart) + 1
+		}
+	}
+
+	start := end - snippetSize
+	if start < 0 {
+		start = 0
+	}
+
+	snippet := make([]byte, 0, end-start)
+	for i := start; i < end; i++ {
+		snippet = append(snippet, r.recent[i%snippetSize])
+	}
+
+	e.Snippet = string(snippet)
+	return &e
+}
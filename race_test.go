@@ -0,0 +1,6 @@
+//go:build race
+
+package jsoon
+
+// raceEnabled reports whether or not the race detector is enabled, which introduces allocations of its own
+const raceEnabled = true
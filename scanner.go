@@ -0,0 +1,582 @@
+package jsoon
+
+import (
+	"bytes"
+	"io"
+)
+
+// maxNesting is the maximum depth of nested objects and arrays accepted by the scanner, matching encoding/json
+const maxNesting = 10000
+
+func newScanner() *scanner {
+	var s scanner
+	s.kb = newBuffer()
+	s.vb = newBuffer()
+	s.v.src = &s
+	return &s
+}
+
+// scanner handles decoding from an in-memory byte slice
+// Note: Unlike Decoder, scanner indexes directly into the source rather than reading through a ReadByter. Keys
+// and values reference the source directly, they are only copied when escape sequences must be decoded
+type scanner struct {
+	// Source data
+	data []byte
+	// Current position within the source data
+	pos int
+
+	// key buffer, only used for keys containing escape sequences
+	kb *buffer
+	// value buffer, only used for strings containing escape sequences
+	vb *buffer
+	// Path to the value currently being decoded
+	path pathStack
+	// Accept NaN, Infinity and -Infinity as numbers
+	allowNaN bool
+	// Depth of the object or array currently being decoded
+	depth int
+
+	v Value
+}
+
+// Decode will decode the source data
+func (s *scanner) Decode(value interface{}) (err error) {
+	if s.skipWhitespace() {
+		return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenObjectOrArray)
+	}
+
+	switch s.data[s.pos] {
+	case charOpenCurly:
+		dec, ok := value.(Decodee)
+		if !ok {
+			return ErrInvalidValue
+		}
+
+		s.pos++
+		if err = s.decodeObject(dec); err != nil {
+			return
+		}
+
+	case charOpenBracket:
+		dec, ok := value.(ArrayDecodee)
+		if !ok {
+			return ErrInvalidValue
+		}
+
+		s.pos++
+		if err = s.decodeArray(dec); err != nil {
+			return
+		}
+
+	default:
+		return s.syntaxError(ErrInvalidChar, s.pos, TokenObjectOrArray)
+	}
+
+	if !s.skipWhitespace() {
+		// Only whitespace may follow the top-level value
+		return s.syntaxError(ErrInvalidChar, s.pos, TokenEnd)
+	}
+
+	return
+}
+
+// Validate will ensure the source data is a single valid JSON value, optionally surrounded by whitespace
+func (s *scanner) Validate() (err error) {
+	if s.skipWhitespace() {
+		return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenValue)
+	}
+
+	if s.v.vt, s.v.b, err = s.readValue(); err != nil {
+		return
+	}
+
+	if err = validateValue(&s.v); err != nil {
+		return
+	}
+
+	if !s.skipWhitespace() {
+		// Only whitespace may follow the top-level value
+		return s.syntaxError(ErrInvalidChar, s.pos, TokenEnd)
+	}
+
+	return
+}
+
+// ReadByte will read a single byte from the source data
+func (s *scanner) ReadByte() (b byte, err error) {
+	if s.pos >= len(s.data) {
+		return 0, io.EOF
+	}
+
+	b = s.data[s.pos]
+	s.pos++
+	return
+}
+
+// UnreadByte will unread the previously read byte
+func (s *scanner) UnreadByte() (err error) {
+	if s.pos == 0 {
+		return io.EOF
+	}
+
+	s.pos--
+	return
+}
+
+func (s *scanner) decodeObject(dec Decodee) (err error) {
+	var (
+		// Current key
+		key []byte
+		// Number of values decoded
+		cnt int
+		// Type of the value passed to the callback, the Value is reused by nested decodes
+		vt ValueType
+	)
+
+	if err = s.enter(); err != nil {
+		return
+	}
+	defer s.exit()
+
+	s.kb.Reset()
+	for {
+		if s.skipWhitespace() {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenKey)
+		}
+
+		if s.data[s.pos] == charCloseCurly && cnt == 0 {
+			// Empty object
+			s.pos++
+			return
+		}
+
+		if s.data[s.pos] != charDoubleQuote {
+			return s.syntaxError(ErrInvalidChar, s.pos, TokenKey)
+		}
+
+		s.pos++
+		if key, err = s.readString(s.kb); err != nil {
+			return
+		}
+
+		if s.skipWhitespace() {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenColon)
+		}
+
+		if s.data[s.pos] != charColon {
+			return s.syntaxError(ErrInvalidChar, s.pos, TokenColon)
+		}
+
+		s.pos++
+		if s.skipWhitespace() {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenValue)
+		}
+
+		if s.v.vt, s.v.b, err = s.readValue(); err != nil {
+			return
+		}
+
+		s.path.pushKey(key)
+		vt = s.v.vt
+		if err = dec.UnmarshalJsoon(unsafeString(key), &s.v); err != nil {
+			return s.path.fieldError(err, vt)
+		}
+
+		// Ensure objects and arrays which were not handled by the Decodee are skipped
+		if err = s.v.Skip(); err != nil {
+			return
+		}
+
+		s.path.pop()
+		s.v.reset()
+		s.kb.Reset()
+		s.vb.Reset()
+		cnt++
+
+		if s.skipWhitespace() {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenObjectSeparator)
+		}
+
+		switch s.data[s.pos] {
+		case charComma:
+			s.pos++
+		case charCloseCurly:
+			s.pos++
+			return
+		default:
+			return s.syntaxError(ErrInvalidChar, s.pos, TokenObjectSeparator)
+		}
+	}
+}
+
+func (s *scanner) decodeArray(dec ArrayDecodee) (err error) {
+	var (
+		// Number of values decoded
+		cnt int
+		// Type of the value passed to the callback, the Value is reused by nested decodes
+		vt ValueType
+	)
+
+	if err = s.enter(); err != nil {
+		return
+	}
+	defer s.exit()
+
+	s.kb.Reset()
+	for {
+		if s.skipWhitespace() {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenValue)
+		}
+
+		if s.data[s.pos] == charCloseBracket && cnt == 0 {
+			// Empty array
+			s.pos++
+			return
+		}
+
+		if s.v.vt, s.v.b, err = s.readValue(); err != nil {
+			return
+		}
+
+		s.path.pushIndex(cnt)
+		vt = s.v.vt
+		if err = dec.UnmarshalJsoon(&s.v); err != nil {
+			return s.path.fieldError(err, vt)
+		}
+
+		// Ensure objects and arrays which were not handled by the ArrayDecodee are skipped
+		if err = s.v.Skip(); err != nil {
+			return
+		}
+
+		s.path.pop()
+		s.v.reset()
+		s.vb.Reset()
+		cnt++
+
+		if s.skipWhitespace() {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenArraySeparator)
+		}
+
+		switch s.data[s.pos] {
+		case charComma:
+			s.pos++
+		case charCloseBracket:
+			s.pos++
+			return
+		default:
+			return s.syntaxError(ErrInvalidChar, s.pos, TokenArraySeparator)
+		}
+	}
+}
+
+// skip will skip the remainder of an object or array whose opening character has already been read
+func (s *scanner) skip() (err error) {
+	var (
+		// Current nesting depth, starts at one to account for the opening character
+		depth = 1
+		// Whether or not we are currently within a string
+		inString bool
+	)
+
+	for ; s.pos < len(s.data); s.pos++ {
+		b := s.data[s.pos]
+		if inString {
+			switch b {
+			case charBackslash:
+				// Skip the escaped character
+				s.pos++
+			case charDoubleQuote:
+				inString = false
+			}
+
+			continue
+		}
+
+		switch b {
+		case charDoubleQuote:
+			inString = true
+		case charOpenCurly, charOpenBracket:
+			depth++
+		case charCloseCurly, charCloseBracket:
+			if depth--; depth == 0 {
+				s.pos++
+				return
+			}
+		}
+	}
+
+	return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenValue)
+}
+
+// skipWhitespace will advance past any whitespace, returning true if the end of the data was reached
+func (s *scanner) skipWhitespace() (end bool) {
+	for ; s.pos < len(s.data); s.pos++ {
+		if !isWhitespace(s.data[s.pos]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readValue will read a value, the returned bytes reference the source data unless escapes were decoded
+func (s *scanner) readValue() (vt ValueType, val []byte, err error) {
+	start := s.pos
+	switch s.data[s.pos] {
+	case charDoubleQuote:
+		s.pos++
+		val, err = s.readString(s.vb)
+		return TypeString, val, err
+
+	case charLowerT:
+		err = s.readLiteral(trueBytes[:])
+		return TypeBool, s.data[start:s.pos], err
+
+	case charLowerF:
+		err = s.readLiteral(falseBytes[:])
+		return TypeBool, s.data[start:s.pos], err
+
+	case charOpenCurly:
+		s.pos++
+		return TypeObject, nil, nil
+
+	case charOpenBracket:
+		s.pos++
+		return TypeArray, nil, nil
+
+	case charLowerN:
+		return TypeNull, nil, s.readLiteral(nullBytes[:])
+
+	case charUpperN:
+		if !s.allowNaN {
+			return TypeNumber, nil, s.syntaxError(ErrInvalidChar, s.pos, TokenValue)
+		}
+
+		err = s.readLiteral(nanBytes[:])
+		return TypeNumber, s.data[start:s.pos], err
+
+	case charUpperI:
+		if !s.allowNaN {
+			return TypeNumber, nil, s.syntaxError(ErrInvalidChar, s.pos, TokenValue)
+		}
+
+		err = s.readLiteral(infinityBytes[:])
+		return TypeNumber, s.data[start:s.pos], err
+
+	default:
+		err = s.readNumber()
+		return TypeNumber, s.data[start:s.pos], err
+	}
+}
+
+// readString will read a string whose opening quote has already been read
+// Note: When the string contains escape sequences, it is decoded into the provided buffer
+func (s *scanner) readString(dst *buffer) (val []byte, err error) {
+	// Start of the current run of unescaped characters
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case charDoubleQuote:
+			val = s.data[start:s.pos]
+			s.pos++
+			return
+
+		case charBackslash:
+			// Escapes must be decoded, so we fall back to copying into the buffer
+			dst.Write(s.data[start:s.pos])
+			return s.appendEscapedString(dst)
+
+		default:
+			if s.data[s.pos] < charSpace {
+				// Control characters must be escaped within strings
+				return nil, s.syntaxError(ErrInvalidChar, s.pos, TokenString)
+			}
+
+			s.pos++
+		}
+	}
+
+	return nil, s.syntaxError(ErrUnexpectedEnd, s.pos, TokenString)
+}
+
+// appendEscapedString will decode the remainder of a string into the provided buffer, starting at an escape
+func (s *scanner) appendEscapedString(dst *buffer) (val []byte, err error) {
+	// Start of the current run of unescaped characters
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case charDoubleQuote:
+			dst.Write(s.data[start:s.pos])
+			s.pos++
+			return dst.Bytes(), nil
+
+		case charBackslash:
+			dst.Write(s.data[start:s.pos])
+			if s.pos += 2; s.pos > len(s.data) {
+				return nil, s.syntaxError(ErrUnexpectedEnd, s.pos, TokenEscape)
+			}
+
+			if err = appendEscape(s, dst, s.data[s.pos-1]); err != nil {
+				// The offending byte is the last byte read while decoding the escape
+				return nil, s.syntaxError(err, s.pos-1, TokenEscape)
+			}
+
+			start = s.pos
+
+		default:
+			if s.data[s.pos] < charSpace {
+				// Control characters must be escaped within strings
+				return nil, s.syntaxError(ErrInvalidChar, s.pos, TokenString)
+			}
+
+			s.pos++
+		}
+	}
+
+	return nil, s.syntaxError(ErrUnexpectedEnd, s.pos, TokenString)
+}
+
+func (s *scanner) readNumber() (err error) {
+	var (
+		// State of our state machine
+		state uint8
+		// Whether or not the current byte continues the number
+		ok bool
+	)
+
+	start := s.pos
+	for ; s.pos < len(s.data); s.pos++ {
+		if state, ok = stepNumber(state, s.data[s.pos]); ok {
+			continue
+		}
+
+		if s.pos == start {
+			// Not a number at all, the value begins with an invalid character
+			return s.syntaxError(ErrInvalidChar, s.pos, TokenValue)
+		}
+
+		if state == nsSign && s.data[s.pos] == charUpperI && s.allowNaN {
+			// Negative infinity
+			return s.readLiteral(infinityBytes[:])
+		}
+
+		if !isNumberComplete(state) || !isNumberEnd(s.data[s.pos]) {
+			return s.syntaxError(ErrInvalidNumber, s.pos, TokenNumber)
+		}
+
+		break
+	}
+
+	if !isNumberComplete(state) {
+		return s.syntaxError(ErrUnexpectedEnd, s.pos, TokenNumber)
+	}
+
+	return
+}
+
+// readLiteral will read a literal (true, false or null), ensuring each byte matches
+func (s *scanner) readLiteral(literal []byte) (err error) {
+	for i := 1; i < len(literal); i++ {
+		if s.pos+i >= len(s.data) {
+			return s.syntaxError(ErrUnexpectedEnd, s.pos+i, TokenLiteral)
+		}
+
+		if s.data[s.pos+i] != literal[i] {
+			return s.syntaxError(ErrInvalidChar, s.pos+i, TokenLiteral)
+		}
+	}
+
+	s.pos += len(literal)
+	return
+}
+
+// syntaxError will attach the position of the offending byte to a syntax sentinel error
+// Note: When the input ended early, the position is the end of the source data
+func (s *scanner) syntaxError(err error, off int, expected TokenClass) error {
+	if !isSyntaxSentinel(err) {
+		// Errors which are already positioned, or which did not originate from the input, are returned as-is
+		return err
+	}
+
+	end := off + 1
+	if err == ErrUnexpectedEnd || off >= len(s.data) {
+		off = len(s.data)
+		end = off
+	}
+
+	e := SyntaxError{
+		Err:      err,
+		Offset:   int64(off),
+		Line:     bytes.Count(s.data[:off], []byte{charNewline}) + 1,
+		Column:   off - bytes.LastIndexByte(s.data[:off], charNewline),
+		Expected: expectationFor(err, expected),
+	}
+
+	if end > off {
+		e.Byte = s.data[off]
+	}
+
+	start := end - snippetSize
+	if start < 0 {
+		start = 0
+	}
+
+	e.Snippet = string(s.data[start:end])
+	return &e
+}
+
+// reset will reset the scanner so it can be used for new source data
+func (s *scanner) reset(data []byte) {
+	s.data = data
+	s.pos = 0
+	s.kb.Reset()
+	s.vb.Reset()
+	s.path.reset()
+	s.allowNaN = false
+	s.depth = 0
+	s.v.reset()
+}
+
+// enter will increase the depth for an object or array whose opening byte has been read, returning an error once
+// the maximum nesting depth is exceeded
+// Note: Decoding recurses once per level of nesting, so the limit prevents untrusted input from overflowing the stack
+func (s *scanner) enter() (err error) {
+	if s.depth >= maxNesting {
+		return s.syntaxError(ErrMaxDepth, s.pos-1, TokenValue)
+	}
+
+	s.depth++
+	return
+}
+
+// exit will reduce the depth to the parent's level
+func (s *scanner) exit() {
+	s.depth--
+}
+
+// validateValue will validate the contents of object and array values, scalar values are validated when read
+func validateValue(val *Value) error {
+	switch val.Type() {
+	case TypeObject:
+		return val.Object(objectValidator{})
+	case TypeArray:
+		return val.Array(arrayValidator{})
+	}
+
+	return nil
+}
+
+// objectValidator is a Decodee which validates every value within an object
+type objectValidator struct{}
+
+func (objectValidator) UnmarshalJsoon(key string, val *Value) error {
+	return validateValue(val)
+}
+
+// arrayValidator is an ArrayDecodee which validates every value within an array
+type arrayValidator struct{}
+
+func (arrayValidator) UnmarshalJsoon(val *Value) error {
+	return validateValue(val)
+}
@@ -0,0 +1,127 @@
+package jsoon
+
+import "strconv"
+
+// Maximum number of bytes of input included within a SyntaxError snippet
+const snippetSize = 16
+
+// TokenClass represents the class of token the decoder expected when a syntax error occurred
+type TokenClass uint8
+
+const (
+	// TokenValue represents any JSON value
+	TokenValue TokenClass = iota
+	// TokenObjectOrArray represents the opening of an object or an array
+	TokenObjectOrArray
+	// TokenKey represents an object key
+	TokenKey
+	// TokenColon represents the colon separating an object key from its value
+	TokenColon
+	// TokenObjectSeparator represents a comma or the closing brace of an object
+	TokenObjectSeparator
+	// TokenArraySeparator represents a comma or the closing bracket of an array
+	TokenArraySeparator
+	// TokenString represents the contents of a string
+	TokenString
+	// TokenEscape represents an escape sequence within a string
+	TokenEscape
+	// TokenNumber represents the contents of a number
+	TokenNumber
+	// TokenLiteral represents the remainder of a literal (true, false or null)
+	TokenLiteral
+	// TokenEnd represents the end of the input
+	TokenEnd
+)
+
+// String will return the name of a token class
+func (tc TokenClass) String() string {
+	switch tc {
+	case TokenValue:
+		return "value"
+	case TokenObjectOrArray:
+		return "object or array"
+	case TokenKey:
+		return "object key"
+	case TokenColon:
+		return "colon"
+	case TokenObjectSeparator:
+		return "comma or closing brace"
+	case TokenArraySeparator:
+		return "comma or closing bracket"
+	case TokenString:
+		return "string character"
+	case TokenEscape:
+		return "escape sequence"
+	case TokenNumber:
+		return "number"
+	case TokenLiteral:
+		return "literal"
+	case TokenEnd:
+		return "end of input"
+	}
+
+	return "unknown"
+}
+
+// SyntaxError is returned when decoding encounters malformed JSON
+// Note: SyntaxError wraps one of the syntax sentinel errors (such as ErrInvalidChar or ErrUnexpectedEnd), so it can
+// be matched using errors.Is
+type SyntaxError struct {
+	// Err is the underlying sentinel error
+	Err error
+	// Offset is the zero-based byte offset of the offending byte, or the length of the input when it ended early
+	Offset int64
+	// Line is the one-based line of the offending byte
+	Line int
+	// Column is the one-based column, in bytes, of the offending byte
+	Column int
+	// Byte is the offending byte, zero when the input ended early
+	Byte byte
+	// Expected is the class of token which was expected
+	Expected TokenClass
+	// Snippet is the input leading up to and including the offending byte
+	Snippet string
+}
+
+// Error will return the error message, including the position of the error
+func (e *SyntaxError) Error() string {
+	msg := e.Err.Error()
+	if e.Err != ErrUnexpectedEnd {
+		msg += " " + strconv.QuoteRune(rune(e.Byte))
+	}
+
+	msg += " at line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column)
+	msg += " (offset " + strconv.FormatInt(e.Offset, 10) + "), expected " + e.Expected.String()
+	if len(e.Snippet) > 0 {
+		msg += " near " + strconv.Quote(e.Snippet)
+	}
+
+	return msg
+}
+
+// Unwrap will return the underlying sentinel error
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// isSyntaxSentinel will return whether or not an error is a bare syntax sentinel which should be given a position
+func isSyntaxSentinel(err error) bool {
+	switch err {
+	case ErrInvalidChar, ErrUnexpectedEnd, ErrInvalidNumber, ErrInvalidEscape, ErrMaxDepth:
+		return true
+	}
+
+	return false
+}
+
+// expectationFor will return the token class expected, preferring the class implied by the error over the fallback
+func expectationFor(err error, fallback TokenClass) TokenClass {
+	switch err {
+	case ErrInvalidNumber:
+		return TokenNumber
+	case ErrInvalidEscape:
+		return TokenEscape
+	}
+
+	return fallback
+}
@@ -1,90 +1,274 @@
 package jsoon
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+)
 
 // Value represents a value
+// Note: A null value is treated as an absent value by every accessor. Object and Array leave the provided
+// value untouched, while the scalar accessors return their zero value. No error is returned in either case,
+// IsNull can be used to tell a null value apart from a present one
 type Value struct {
 	// Value type
-	vt uint8
-	// Reference decoder
-	d *Decoder
+	vt ValueType
+	// Whether or not the contents of an object or array value have been read
+	consumed bool
+	// Value bytes
+	b []byte
+	// Source of the value, used to decode object and array values
+	src valueSource
+}
+
+// Type will return the type of the value
+func (v *Value) Type() ValueType {
+	return v.vt
+}
+
+// IsNull will return whether or not the value is null
+func (v *Value) IsNull() bool {
+	return v.vt == TypeNull
 }
 
 // Object will associate a provided value with an object
 func (v *Value) Object(val Decodee) (err error) {
-	if v.vt == valNil {
+	if v.vt == TypeNull {
 		return
 	}
 
-	if v.vt != valObject {
+	if v.vt != TypeObject {
 		return ErrValueNotObject
 	}
 
-	v.d.kb.Reset()
-	if err = v.d.decodeObject(val); err != nil {
+	if err = v.src.decodeObject(val); err != nil {
 		return
 	}
 
+	v.consumed = true
 	return
 }
 
 // Array will associate a provided value with an array
 func (v *Value) Array(val ArrayDecodee) (err error) {
-	if v.vt == valNil {
+	if v.vt == TypeNull {
 		return
 	}
 
-	if v.vt != valArray {
+	if v.vt != TypeArray {
 		return ErrValueNotArray
 	}
 
-	v.d.kb.Reset()
-	if err = v.d.decodeArray(val); err != nil {
+	if err = v.src.decodeArray(val); err != nil {
+		return
+	}
+
+	v.consumed = true
+	return
+}
+
+// Skip will skip the contents of an object or array value. Skip is called automatically for any object or array
+// values which are not handled by a Decodee or ArrayDecodee, so it only needs to be called explicitly when a
+// value must be discarded before another operation is performed
+func (v *Value) Skip() (err error) {
+	if v.consumed || (v.vt != TypeObject && v.vt != TypeArray) {
+		// Scalar values are read in their entirety before being provided, so there is nothing to skip
+		return
+	}
+
+	if err = v.src.skip(); err != nil {
 		return
 	}
 
+	v.consumed = true
 	return
 }
 
+// String will return a string value
 func (v *Value) String() (val string, err error) {
-	if v.vt != valString {
+	if v.vt == TypeNull {
+		return
+	}
+
+	if v.vt != TypeString {
 		err = ErrValueNotString
 		return
 	}
 
-	val = string(v.d.vb.Bytes())
+	val = string(v.b)
 	return
 }
 
 // Bytes will return bytes
 // Note: Please do not hold onto the val after it's initially returned. Use it, let it spread it's wings and fly
+// When decoding with Unmarshal, val may reference the source data directly, so it must not be modified either
 func (v *Value) Bytes() (val []byte, err error) {
-	if v.vt != valString {
+	if v.vt == TypeNull {
+		return
+	}
+
+	if v.vt != TypeString {
 		err = ErrValueNotBytes
 		return
 	}
 
-	val = v.d.vb.Bytes()
+	val = v.b
 	return
 }
 
 // Number will return a number value
 func (v *Value) Number() (val float64, err error) {
-	if v.vt != valNumber {
+	if v.vt == TypeNull {
+		return
+	}
+
+	if v.vt != TypeNumber {
 		err = ErrValueNotNumber
 		return
 	}
 
-	return strconv.ParseFloat(unsafeString(v.d.vb.Bytes()), 64)
+	return strconv.ParseFloat(unsafeString(v.b), 64)
+}
+
+// Int will return an int value
+func (v *Value) Int() (val int, err error) {
+	var n int64
+	n, err = v.parseInt(strconv.IntSize)
+	val = int(n)
+	return
+}
+
+// Int8 will return an int8 value
+func (v *Value) Int8() (val int8, err error) {
+	var n int64
+	n, err = v.parseInt(8)
+	val = int8(n)
+	return
+}
+
+// Int16 will return an int16 value
+func (v *Value) Int16() (val int16, err error) {
+	var n int64
+	n, err = v.parseInt(16)
+	val = int16(n)
+	return
+}
+
+// Int32 will return an int32 value
+func (v *Value) Int32() (val int32, err error) {
+	var n int64
+	n, err = v.parseInt(32)
+	val = int32(n)
+	return
+}
+
+// Int64 will return an int64 value
+func (v *Value) Int64() (val int64, err error) {
+	return v.parseInt(64)
+}
+
+// Uint will return a uint value
+func (v *Value) Uint() (val uint, err error) {
+	var n uint64
+	n, err = v.parseUint(math.MaxUint)
+	val = uint(n)
+	return
+}
+
+// Uint8 will return a uint8 value
+func (v *Value) Uint8() (val uint8, err error) {
+	var n uint64
+	n, err = v.parseUint(math.MaxUint8)
+	val = uint8(n)
+	return
+}
+
+// Uint16 will return a uint16 value
+func (v *Value) Uint16() (val uint16, err error) {
+	var n uint64
+	n, err = v.parseUint(math.MaxUint16)
+	val = uint16(n)
+	return
+}
+
+// Uint32 will return a uint32 value
+func (v *Value) Uint32() (val uint32, err error) {
+	var n uint64
+	n, err = v.parseUint(math.MaxUint32)
+	val = uint32(n)
+	return
+}
+
+// Uint64 will return a uint64 value
+func (v *Value) Uint64() (val uint64, err error) {
+	return v.parseUint(math.MaxUint64)
 }
 
 // Bool will return a boolean value
 func (v *Value) Bool() (val bool, err error) {
-	if v.vt != valBool {
+	if v.vt == TypeNull {
+		return
+	}
+
+	if v.vt != TypeBool {
 		err = ErrValueNotBool
 		return
 	}
 
-	val = len(v.d.vb.Bytes()) == 4
+	val = len(v.b) == 4
 	return
 }
+
+func (v *Value) parseInt(bitSize uint) (val int64, err error) {
+	if v.vt == TypeNull {
+		return
+	}
+
+	if v.vt != TypeNumber {
+		err = ErrValueNotNumber
+		return
+	}
+
+	return parseInt(v.b, bitSize)
+}
+
+func (v *Value) parseUint(max uint64) (val uint64, err error) {
+	if v.vt == TypeNull {
+		return
+	}
+
+	if v.vt != TypeNumber {
+		err = ErrValueNotNumber
+		return
+	}
+
+	vb := v.b
+	if len(vb) > 0 && vb[0] == charHyphen {
+		// Negative numbers (other than negative zero) cannot be represented as an unsigned integer
+		if val, err = parseUint(vb[1:], math.MaxUint64); err == ErrValueNotInteger {
+			return
+		}
+
+		if err != nil || val != 0 {
+			val = 0
+			err = ErrValueOverflow
+		}
+
+		return
+	}
+
+	return parseUint(vb, max)
+}
+
+// reset will reset the value so it can be used for the next value
+func (v *Value) reset() {
+	v.vt = TypeNull
+	v.consumed = false
+	v.b = nil
+}
+
+// valueSource is a source which object and array values are decoded from
+type valueSource interface {
+	decodeObject(dec Decodee) error
+	decodeArray(dec ArrayDecodee) error
+	skip() error
+}